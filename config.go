@@ -80,7 +80,7 @@ func (vmm *Vmm) ConfigGet(ctx context.Context, option uint64) (uint64, error) {
 	respChan := make(chan struct {
 		value uint64
 		err   error
-	})
+	}, 1)
 
 	go func() {
 		var value uint64
@@ -113,8 +113,11 @@ func (vmm *Vmm) ConfigSet(ctx context.Context, option uint64, value uint64) erro
 	go func() {
 		success := C.VMMDLL_ConfigSet(C.VMM_HANDLE(vmm.handle), C.ULONG64(option), C.ULONG64(value))
 		if success == 0 {
-			errChan <- errors.New("VMMDLL_ConfigGet: failed to set config")
+			errChan <- errors.New("VMMDLL_ConfigSet: failed to set config")
+			return
 		}
+
+		errChan <- nil
 	}()
 
 	select {
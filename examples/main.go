@@ -7,7 +7,6 @@ import (
 	"github.com/sergeyzav/memprocfs"
 	"github.com/sergeyzav/memprocfs/memory"
 	"time"
-	"unsafe"
 )
 
 func main() {
@@ -173,16 +172,15 @@ func main() {
 	if err != nil {
 		fmt.Println(err)
 	}
-	buff := make([]byte, 40)
-	err = task.PrepareRead(context.TODO(), 140733155704832, 5, unsafe.Pointer(&buff[0]))
+	r0, err := task.PrepareRead(context.TODO(), 140733155704832, 5)
 	if err != nil {
 		fmt.Println(err)
 	}
-	err = task.PrepareRead(context.TODO(), 140733155704832+5, 15, unsafe.Pointer(&buff[5]))
+	r5, err := task.PrepareRead(context.TODO(), 140733155704832+5, 15)
 	if err != nil {
 		fmt.Println(err)
 	}
-	err = task.PrepareRead(context.TODO(), 140733155704832+20, 20, unsafe.Pointer(&buff[20]))
+	r20, err := task.PrepareRead(context.TODO(), 140733155704832+20, 20)
 	if err != nil {
 		fmt.Println(err)
 	}
@@ -197,6 +195,13 @@ func main() {
 		fmt.Println(err)
 	}
 
+	var buff []byte
+	for _, r := range []*go_memprocfs.ScatterResult{r0, r5, r20} {
+		if r != nil {
+			buff = append(buff, r.Bytes()...)
+		}
+	}
+
 	fmt.Println("===== MEM SCATTER READ =====", prettyPrint(buff))
 
 	task1, err := vmm.NewScatterTask(explorerPid, 0x3)
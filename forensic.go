@@ -0,0 +1,155 @@
+package go_memprocfs
+
+/*
+#include <stdlib.h>
+#include "vmmdll.h"
+
+// cgo can't call variadic functions, the text is formatted in Go instead.
+static BOOL forensicFileAppend(VMM_HANDLE H, LPCSTR uszFileName, LPCSTR uszText) {
+	return VMMDLL_ForensicFileAppend(H, uszFileName, "%s", uszText);
+}
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// ForensicMode is the value of OptForensicMode.
+type ForensicMode uint64
+
+const (
+	ForensicModeNone           ForensicMode = 0 // forensic mode disabled
+	ForensicModeInMemory       ForensicMode = 1 // in-memory sqlite database
+	ForensicModeTempFile       ForensicMode = 2 // temporary sqlite database
+	ForensicModeTempFileDelete ForensicMode = 3 // temporary sqlite database deleted on exit
+	ForensicModeStaticFile     ForensicMode = 4 // static sqlite database (vmm.sqlite3)
+)
+
+const (
+	forensicProgressFile         = "/forensic/progress_percent.txt"
+	forensicProgressPollInterval = 500 * time.Millisecond
+)
+
+func (m ForensicMode) String() string {
+	switch m {
+	case ForensicModeNone:
+		return "None"
+	case ForensicModeInMemory:
+		return "InMemory"
+	case ForensicModeTempFile:
+		return "TempFile"
+	case ForensicModeTempFileDelete:
+		return "TempFileDelete"
+	case ForensicModeStaticFile:
+		return "StaticFile"
+	default:
+		return "Unknown"
+	}
+}
+
+// ForensicProgress returns the forensic scan progress in percent.
+func (v *Vmm) ForensicProgress(ctx context.Context) (uint32, error) {
+	data, err := v.VfsRead(ctx, forensicProgressFile, 0, 16)
+	if err != nil && len(data) == 0 {
+		return 0, err
+	}
+
+	progress, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse forensic progress %q: %w", data, err)
+	}
+
+	return uint32(progress), nil
+}
+
+// StartForensic enables forensic mode and waits for the forensic scan to finish.
+// Progress in percent is sent on progress, if not nil, each time it changes.
+//
+// Forensic mode can only be enabled once. If it's already enabled with the same
+// mode StartForensic just waits for the scan to finish.
+func (v *Vmm) StartForensic(ctx context.Context, mode ForensicMode, progress chan<- uint32) error {
+	if mode == ForensicModeNone || mode > ForensicModeStaticFile {
+		return fmt.Errorf("invalid forensic mode %d", mode)
+	}
+
+	current, err := v.ConfigGet(ctx, OptForensicMode)
+	if err != nil {
+		return err
+	}
+
+	switch ForensicMode(current) {
+	case ForensicModeNone:
+		if err := v.ConfigSet(ctx, OptForensicMode, uint64(mode)); err != nil {
+			return err
+		}
+	case mode:
+	default:
+		return fmt.Errorf("forensic mode already enabled as %s", ForensicMode(current))
+	}
+
+	ticker := time.NewTicker(forensicProgressPollInterval)
+	defer ticker.Stop()
+
+	last := ^uint32(0)
+	for {
+		percent, err := v.ForensicProgress(ctx)
+		if err != nil {
+			return err
+		}
+
+		if percent != last && progress != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case progress <- percent:
+			}
+		}
+		last = percent
+
+		if percent >= 100 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (v *Vmm) forensicAppend(file string, text string) error {
+	cFile := C.CString(file)
+	defer C.free(unsafe.Pointer(cFile))
+	cText := C.CString(text)
+	defer C.free(unsafe.Pointer(cText))
+
+	if C.forensicFileAppend(v.handle, cFile, cText) == 0 {
+		return fmt.Errorf("failed to append to forensic file %s", file)
+	}
+	return nil
+}
+
+// ForensicAppend appends fmt.Sprintf(format, args...) to the memory backed forensic
+// file with the given name. The file shows up in the /forensic/ directory next to the
+// built-in outputs. Forensic mode must be enabled, see StartForensic.
+func (v *Vmm) ForensicAppend(ctx context.Context, file string, format string, args ...any) error {
+	text := fmt.Sprintf(format, args...)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- v.forensicAppend(file, text)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
@@ -0,0 +1,168 @@
+package go_memprocfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ForensicReportFormat selects the row encoding of a ForensicReport.
+type ForensicReportFormat int
+
+const (
+	// ForensicReportCSV writes a header line followed by one CSV record per row.
+	ForensicReportCSV ForensicReportFormat = iota
+
+	// ForensicReportJSON writes one JSON object per row (JSON Lines), keyed by column.
+	ForensicReportJSON
+)
+
+func (f ForensicReportFormat) String() string {
+	switch f {
+	case ForensicReportCSV:
+		return "CSV"
+	case ForensicReportJSON:
+		return "JSON"
+	default:
+		return "Unknown"
+	}
+}
+
+var ErrForensicReportColumns = errors.New("number of values doesn't match the report columns")
+
+// ForensicReport writes rows of custom analysis results into a forensic file with
+// ForensicAppend. It's safe for concurrent use.
+type ForensicReport struct {
+	vmm     *Vmm
+	file    string
+	format  ForensicReportFormat
+	columns []string
+
+	mu            sync.Mutex
+	headerWritten bool
+}
+
+// NewForensicReport creates a report writing to file, e.g. "findings.csv".
+func (v *Vmm) NewForensicReport(file string, format ForensicReportFormat, columns ...string) *ForensicReport {
+	return &ForensicReport{
+		vmm:     v,
+		file:    file,
+		format:  format,
+		columns: columns,
+	}
+}
+
+func (r *ForensicReport) File() string {
+	return r.file
+}
+
+func (r *ForensicReport) Columns() []string {
+	return r.columns
+}
+
+// WriteRow appends a row with one value per column. Values are written with fmt.Sprint
+// in CSV reports and as JSON values in JSON reports.
+//
+// If ctx is done first WriteRow returns ctx.Err() but the row is still appended, so
+// retrying a canceled WriteRow writes the row twice.
+func (r *ForensicReport) WriteRow(ctx context.Context, values ...any) error {
+	if len(values) != len(r.columns) {
+		return ErrForensicReportColumns
+	}
+
+	errChan := make(chan error, 1)
+
+	// The append runs to completion even if ctx is done first, the header state is
+	// only updated from its real result so a canceled row can't repeat the header.
+	go func() {
+		errChan <- r.writeRow(values)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
+
+func (r *ForensicReport) writeRow(values []any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var text []byte
+	var err error
+
+	switch r.format {
+	case ForensicReportCSV:
+		text, err = r.csvRow(values)
+	case ForensicReportJSON:
+		text, err = r.jsonRow(values)
+	default:
+		return fmt.Errorf("unsupported forensic report format %d", r.format)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := r.vmm.forensicAppend(r.file, string(text)); err != nil {
+		return err
+	}
+
+	r.headerWritten = true
+	return nil
+}
+
+func (r *ForensicReport) csvRow(values []any) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if !r.headerWritten {
+		if err := writer.Write(r.columns); err != nil {
+			return nil, err
+		}
+	}
+
+	record := make([]string, len(values))
+	for i, value := range values {
+		record[i] = fmt.Sprint(value)
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+
+	writer.Flush()
+	return buffer.Bytes(), writer.Error()
+}
+
+func (r *ForensicReport) jsonRow(values []any) ([]byte, error) {
+	// Encoded by hand to keep the column order.
+	var buffer bytes.Buffer
+	buffer.WriteByte('{')
+
+	for i, column := range r.columns {
+		if i > 0 {
+			buffer.WriteByte(',')
+		}
+
+		key, err := json.Marshal(column)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		buffer.Write(key)
+		buffer.WriteByte(':')
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}\n")
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,101 @@
+package go_memprocfs
+
+import (
+	"context"
+	"testing"
+)
+
+func TestForensicReportCSVRow(t *testing.T) {
+	tests := []struct {
+		name          string
+		headerWritten bool
+		values        []any
+		want          string
+	}{
+		{
+			name:   "first row has header",
+			values: []any{uint32(4), "System", true},
+			want:   "PID,Name,Suspicious\n4,System,true\n",
+		},
+		{
+			name:          "later row",
+			headerWritten: true,
+			values:        []any{uint32(3328), "svchost.exe", false},
+			want:          "3328,svchost.exe,false\n",
+		},
+		{
+			name:          "quoting",
+			headerWritten: true,
+			values:        []any{1, "a,b \"c\"", nil},
+			want:          "1,\"a,b \"\"c\"\"\",<nil>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ForensicReport{columns: []string{"PID", "Name", "Suspicious"}, headerWritten: tt.headerWritten}
+			got, err := r.csvRow(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("csvRow = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForensicReportJSONRow(t *testing.T) {
+	tests := []struct {
+		name    string
+		columns []string
+		values  []any
+		want    string
+	}{
+		{
+			name:    "column order",
+			columns: []string{"Name", "PID", "Address"},
+			values:  []any{"System", uint32(4), uint64(0xfffff80000000000)},
+			want:    "{\"Name\":\"System\",\"PID\":4,\"Address\":18446735277616529408}\n",
+		},
+		{
+			name:    "escaping",
+			columns: []string{"path \"x\""},
+			values:  []any{"C:\\Windows\n"},
+			want:    "{\"path \\\"x\\\"\":\"C:\\\\Windows\\n\"}\n",
+		},
+		{
+			name:    "nested values",
+			columns: []string{"Tags", "Parent"},
+			values:  []any{[]string{"a", "b"}, nil},
+			want:    "{\"Tags\":[\"a\",\"b\"],\"Parent\":null}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &ForensicReport{format: ForensicReportJSON, columns: tt.columns}
+			got, err := r.jsonRow(tt.values)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("jsonRow = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestForensicReportJSONRowError(t *testing.T) {
+	r := &ForensicReport{format: ForensicReportJSON, columns: []string{"Channel"}}
+	if _, err := r.jsonRow([]any{make(chan int)}); err == nil {
+		t.Error("jsonRow of an unsupported value succeeded")
+	}
+}
+
+func TestForensicReportWriteRowColumns(t *testing.T) {
+	r := &ForensicReport{columns: []string{"PID", "Name"}}
+	if err := r.WriteRow(context.Background(), 4); err != ErrForensicReportColumns {
+		t.Errorf("WriteRow error = %v, want ErrForensicReportColumns", err)
+	}
+}
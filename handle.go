@@ -0,0 +1,318 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unsafe"
+)
+
+// AccessMask is a Windows ACCESS_MASK as granted to a handle.
+type AccessMask uint32
+
+type accessRight struct {
+	mask AccessMask
+	name string
+}
+
+var standardAccessRights = []accessRight{
+	{0x00010000, "DELETE"},
+	{0x00020000, "READ_CONTROL"},
+	{0x00040000, "WRITE_DAC"},
+	{0x00080000, "WRITE_OWNER"},
+	{0x00100000, "SYNCHRONIZE"},
+	{0x01000000, "ACCESS_SYSTEM_SECURITY"},
+	{0x02000000, "MAXIMUM_ALLOWED"},
+	{0x10000000, "GENERIC_ALL"},
+	{0x20000000, "GENERIC_EXECUTE"},
+	{0x40000000, "GENERIC_WRITE"},
+	{0x80000000, "GENERIC_READ"},
+}
+
+var specificAccessRights = map[string][]accessRight{
+	"Process": {
+		{0x0001, "PROCESS_TERMINATE"},
+		{0x0002, "PROCESS_CREATE_THREAD"},
+		{0x0004, "PROCESS_SET_SESSIONID"},
+		{0x0008, "PROCESS_VM_OPERATION"},
+		{0x0010, "PROCESS_VM_READ"},
+		{0x0020, "PROCESS_VM_WRITE"},
+		{0x0040, "PROCESS_DUP_HANDLE"},
+		{0x0080, "PROCESS_CREATE_PROCESS"},
+		{0x0100, "PROCESS_SET_QUOTA"},
+		{0x0200, "PROCESS_SET_INFORMATION"},
+		{0x0400, "PROCESS_QUERY_INFORMATION"},
+		{0x0800, "PROCESS_SUSPEND_RESUME"},
+		{0x1000, "PROCESS_QUERY_LIMITED_INFORMATION"},
+		{0x2000, "PROCESS_SET_LIMITED_INFORMATION"},
+	},
+	"Thread": {
+		{0x0001, "THREAD_TERMINATE"},
+		{0x0002, "THREAD_SUSPEND_RESUME"},
+		{0x0004, "THREAD_ALERT"},
+		{0x0008, "THREAD_GET_CONTEXT"},
+		{0x0010, "THREAD_SET_CONTEXT"},
+		{0x0020, "THREAD_SET_INFORMATION"},
+		{0x0040, "THREAD_QUERY_INFORMATION"},
+		{0x0080, "THREAD_SET_THREAD_TOKEN"},
+		{0x0100, "THREAD_IMPERSONATE"},
+		{0x0200, "THREAD_DIRECT_IMPERSONATION"},
+		{0x0400, "THREAD_SET_LIMITED_INFORMATION"},
+		{0x0800, "THREAD_QUERY_LIMITED_INFORMATION"},
+		{0x1000, "THREAD_RESUME"},
+	},
+	"File": {
+		{0x0001, "FILE_READ_DATA"},
+		{0x0002, "FILE_WRITE_DATA"},
+		{0x0004, "FILE_APPEND_DATA"},
+		{0x0008, "FILE_READ_EA"},
+		{0x0010, "FILE_WRITE_EA"},
+		{0x0020, "FILE_EXECUTE"},
+		{0x0040, "FILE_DELETE_CHILD"},
+		{0x0080, "FILE_READ_ATTRIBUTES"},
+		{0x0100, "FILE_WRITE_ATTRIBUTES"},
+	},
+	"Key": {
+		{0x0001, "KEY_QUERY_VALUE"},
+		{0x0002, "KEY_SET_VALUE"},
+		{0x0004, "KEY_CREATE_SUB_KEY"},
+		{0x0008, "KEY_ENUMERATE_SUB_KEYS"},
+		{0x0010, "KEY_NOTIFY"},
+		{0x0020, "KEY_CREATE_LINK"},
+		{0x0100, "KEY_WOW64_64KEY"},
+		{0x0200, "KEY_WOW64_32KEY"},
+	},
+}
+
+// Rights decodes the access mask into named rights. Object specific rights are only
+// decoded for the Process, Thread, File and Key types; for other types the remaining
+// specific bits are reported as a single hex value.
+func (a AccessMask) Rights(typeName string) []string {
+	var rights []string
+	remaining := a
+
+	for _, right := range specificAccessRights[typeName] {
+		if a&right.mask == right.mask {
+			rights = append(rights, right.name)
+			remaining &^= right.mask
+		}
+	}
+
+	for _, right := range standardAccessRights {
+		if a&right.mask == right.mask {
+			rights = append(rights, right.name)
+			remaining &^= right.mask
+		}
+	}
+
+	if remaining != 0 {
+		rights = append(rights, fmt.Sprintf("0x%x", uint32(remaining)))
+	}
+
+	return rights
+}
+
+type HandleEntry struct {
+	VaObject             uint64
+	Handle               uint32
+	GrantedAccess        AccessMask
+	TypeIndex            uint8
+	HandleCount          uint64
+	PointerCount         uint64
+	VaObjectCreateInfo   uint64
+	VaSecurityDescriptor uint64
+	Name                 string
+	PID                  uint32
+	PoolTag              string
+	Type                 string
+}
+
+// AccessRights returns the named rights granted to the handle.
+func (e HandleEntry) AccessRights() []string {
+	return e.GrantedAccess.Rights(e.Type)
+}
+
+type Handle struct {
+	Version   uint32
+	MultiText []string
+	Entries   []HandleEntry
+}
+
+// HandleFilter reports whether a handle entry should be kept.
+type HandleFilter func(entry *HandleEntry) bool
+
+// HandleTypeFilter keeps handles of any of the given object types, e.g. "File", "Key" or "Process".
+func HandleTypeFilter(types ...string) HandleFilter {
+	return func(entry *HandleEntry) bool {
+		for _, t := range types {
+			if strings.EqualFold(entry.Type, t) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HandleObjectFilter keeps handles referencing the kernel object at va, e.g. the
+// EPROCESS address (ProcessInformation.Win.VaEPROCESS) of a process.
+func HandleObjectFilter(va uint64) HandleFilter {
+	return func(entry *HandleEntry) bool {
+		return entry.VaObject == va
+	}
+}
+
+// Filter returns the handle entries matching all given filters.
+func (h *Handle) Filter(filters ...HandleFilter) []HandleEntry {
+	var result []HandleEntry
+	for i := range h.Entries {
+		if matchHandle(&h.Entries[i], filters) {
+			result = append(result, h.Entries[i])
+		}
+	}
+	return result
+}
+
+// ByType returns the handle entries of any of the given object types.
+func (h *Handle) ByType(types ...string) []HandleEntry {
+	return h.Filter(HandleTypeFilter(types...))
+}
+
+func matchHandle(entry *HandleEntry, filters []HandleFilter) bool {
+	for _, filter := range filters {
+		if !filter(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func newHandleEntry(cEntry *C.VMMDLL_MAP_HANDLEENTRY) HandleEntry {
+	// DWORD dwGrantedAccess : 24; DWORD iType : 8;
+	bits := *(*uint32)(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.dwHandle), unsafe.Sizeof(cEntry.dwHandle)))
+	uszTextPtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.vaSecurityDescriptor), unsafe.Sizeof(cEntry.vaSecurityDescriptor))
+	uszTypePtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry._FutureUse), unsafe.Sizeof(cEntry._FutureUse))
+
+	return HandleEntry{
+		VaObject:             uint64(cEntry.vaObject),
+		Handle:               uint32(cEntry.dwHandle),
+		GrantedAccess:        AccessMask(bits & 0x00FFFFFF),
+		TypeIndex:            uint8(bits >> 24),
+		HandleCount:          uint64(cEntry.qwHandleCount),
+		PointerCount:         uint64(cEntry.qwPointerCount),
+		VaObjectCreateInfo:   uint64(cEntry.vaObjectCreateInfo),
+		VaSecurityDescriptor: uint64(cEntry.vaSecurityDescriptor),
+		Name:                 C.GoString(*(**C.char)(uszTextPtr)),
+		PID:                  uint32(cEntry.dwPID),
+		PoolTag:              poolTagString(uint32(cEntry.dwPoolTag)),
+		Type:                 C.GoString(*(**C.char)(uszTypePtr)),
+	}
+}
+
+func newHandle(cHandle *C.VMMDLL_MAP_HANDLE) Handle {
+	handle := Handle{
+		Version: uint32(cHandle.dwVersion),
+	}
+
+	if cHandle.pbMultiText != nil && cHandle.cbMultiText > 0 {
+		handle.MultiText = multiString(C.GoBytes(unsafe.Pointer(cHandle.pbMultiText), C.int(cHandle.cbMultiText)))
+	}
+
+	count := int(cHandle.cMap)
+	handle.Entries = make([]HandleEntry, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_HANDLEENTRY](afterDWORD(unsafe.Pointer(&cHandle.cMap)), count) {
+		handle.Entries[i] = newHandleEntry(cEntry)
+	}
+
+	return handle
+}
+
+func (vmm *Vmm) getProcessMapHandle(pid uint32) (*Handle, error) {
+	var cHandleMap C.PVMMDLL_MAP_HANDLE
+	success := C.VMMDLL_Map_GetHandleU(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), &cHandleMap)
+
+	if success == 0 || cHandleMap == nil {
+		return nil, fmt.Errorf("failed to get handle map for process %d", pid)
+	}
+
+	defer freeMemory(C.PVOID(cHandleMap))
+
+	if cHandleMap.dwVersion != MapHandleVersion {
+		return nil, ErrUnsupportedHandleVersion
+	}
+
+	handle := newHandle(cHandleMap)
+
+	return &handle, nil
+}
+
+func (vmm *Vmm) GetProcessMapHandle(ctx context.Context, pid uint32) (*Handle, error) {
+	resultChan := make(chan struct {
+		handle *Handle
+		err    error
+	}, 1)
+
+	go func() {
+		handle, err := vmm.getProcessMapHandle(pid)
+		resultChan <- struct {
+			handle *Handle
+			err    error
+		}{handle, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.handle, result.err
+	}
+}
+
+func (vmm *Vmm) findHandles(filters []HandleFilter) (map[uint32][]HandleEntry, error) {
+	pids, err := vmm.getPidList()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint32][]HandleEntry)
+	for _, pid := range pids {
+		handle, err := vmm.getProcessMapHandle(pid)
+		if err != nil {
+			continue
+		}
+		if entries := handle.Filter(filters...); len(entries) > 0 {
+			result[pid] = entries
+		}
+	}
+
+	return result, nil
+}
+
+// FindHandles walks the handle tables of all processes and returns the matching
+// handles grouped by the PID holding them. Processes whose handle table cannot be
+// read are skipped.
+func (vmm *Vmm) FindHandles(ctx context.Context, filters ...HandleFilter) (map[uint32][]HandleEntry, error) {
+	resultChan := make(chan struct {
+		handles map[uint32][]HandleEntry
+		err     error
+	}, 1)
+
+	go func() {
+		handles, err := vmm.findHandles(filters)
+		resultChan <- struct {
+			handles map[uint32][]HandleEntry
+			err     error
+		}{handles, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.handles, result.err
+	}
+}
@@ -0,0 +1,350 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+type HeapType uint32
+
+const (
+	HeapTypeNA HeapType = iota
+	HeapTypeNT
+	HeapTypeSegment
+)
+
+func (h HeapType) String() string {
+	switch h {
+	case HeapTypeNA:
+		return "N/A"
+	case HeapTypeNT:
+		return "NT"
+	case HeapTypeSegment:
+		return "Segment"
+	default:
+		return "Unknown"
+	}
+}
+
+type HeapSegmentType uint16
+
+const (
+	HeapSegmentTypeNA HeapSegmentType = iota
+	HeapSegmentTypeNTSegment
+	HeapSegmentTypeNTLFH
+	HeapSegmentTypeNTLarge
+	HeapSegmentTypeNTNA
+	HeapSegmentTypeSegHeap
+	HeapSegmentTypeSegSegment
+	HeapSegmentTypeSegLarge
+	HeapSegmentTypeSegNA
+)
+
+func (h HeapSegmentType) String() string {
+	switch h {
+	case HeapSegmentTypeNA:
+		return "N/A"
+	case HeapSegmentTypeNTSegment:
+		return "NtSegment"
+	case HeapSegmentTypeNTLFH:
+		return "NtLFH"
+	case HeapSegmentTypeNTLarge:
+		return "NtLarge"
+	case HeapSegmentTypeNTNA:
+		return "NtNA"
+	case HeapSegmentTypeSegHeap:
+		return "SegHeap"
+	case HeapSegmentTypeSegSegment:
+		return "SegSegment"
+	case HeapSegmentTypeSegLarge:
+		return "SegLarge"
+	case HeapSegmentTypeSegNA:
+		return "SegNA"
+	default:
+		return "Unknown"
+	}
+}
+
+type HeapAllocType uint16
+
+const (
+	HeapAllocTypeNA HeapAllocType = iota
+	HeapAllocTypeNTHeap
+	HeapAllocTypeNTLFH
+	HeapAllocTypeNTLarge
+	HeapAllocTypeNTNA
+	HeapAllocTypeSegVS
+	HeapAllocTypeSegLFH
+	HeapAllocTypeSegLarge
+	HeapAllocTypeSegNA
+)
+
+func (h HeapAllocType) String() string {
+	switch h {
+	case HeapAllocTypeNA:
+		return "N/A"
+	case HeapAllocTypeNTHeap:
+		return "NtHeap"
+	case HeapAllocTypeNTLFH:
+		return "NtLFH"
+	case HeapAllocTypeNTLarge:
+		return "NtLarge"
+	case HeapAllocTypeNTNA:
+		return "NtNA"
+	case HeapAllocTypeSegVS:
+		return "SegVS"
+	case HeapAllocTypeSegLFH:
+		return "SegLFH"
+	case HeapAllocTypeSegLarge:
+		return "SegLarge"
+	case HeapAllocTypeSegNA:
+		return "SegNA"
+	default:
+		return "Unknown"
+	}
+}
+
+var ErrNotHeapVAD = errors.New("VAD entry is not a heap")
+
+type HeapSegmentEntry struct {
+	Va        uint64
+	Size      uint32
+	Type      HeapSegmentType
+	HeapIndex uint16
+}
+
+type HeapEntry struct {
+	Va       uint64
+	Type     HeapType
+	Is32     bool
+	Index    uint32
+	HeapNum  uint32
+	Segments []HeapSegmentEntry
+}
+
+type Heap struct {
+	Version  uint32
+	Entries  []HeapEntry
+	Segments []HeapSegmentEntry
+}
+
+// ForVAD returns the heap backing a VAD entry with IsHeap set.
+func (h *Heap) ForVAD(vad *VADEntry) (*HeapEntry, bool) {
+	if vad == nil || !vad.IsHeap {
+		return nil, false
+	}
+	for i := range h.Entries {
+		if h.Entries[i].HeapNum == uint32(vad.HeapNum) {
+			return &h.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+type HeapAllocEntry struct {
+	Va        uint64
+	Size      uint32
+	Type      HeapAllocType
+	HeapIndex uint16
+}
+
+type HeapAlloc struct {
+	Version uint32
+	Entries []HeapAllocEntry
+}
+
+// InRange returns the allocations located in [vaStart, vaEnd].
+func (h *HeapAlloc) InRange(vaStart uint64, vaEnd uint64) []HeapAllocEntry {
+	var result []HeapAllocEntry
+	for _, entry := range h.Entries {
+		if entry.Va >= vaStart && entry.Va <= vaEnd {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+func newHeapSegmentEntry(cEntry *C.VMMDLL_MAP_HEAP_SEGMENTENTRY) HeapSegmentEntry {
+	// VMMDLL_HEAP_SEGMENT_TP tp : 16; DWORD iHeap : 16;
+	bits := *(*uint32)(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.cb), unsafe.Sizeof(cEntry.cb)))
+
+	return HeapSegmentEntry{
+		Va:        uint64(cEntry.va),
+		Size:      uint32(cEntry.cb),
+		Type:      HeapSegmentType(bits & 0xFFFF),
+		HeapIndex: uint16(bits >> 16),
+	}
+}
+
+func newHeapEntry(cEntry *C.VMMDLL_MAP_HEAPENTRY) HeapEntry {
+	return HeapEntry{
+		Va:      uint64(cEntry.va),
+		Type:    HeapType(cEntry.tp),
+		Is32:    cEntry.f32 != 0,
+		Index:   uint32(cEntry.iHeap),
+		HeapNum: uint32(cEntry.dwHeapNum),
+	}
+}
+
+func newHeap(cHeap *C.VMMDLL_MAP_HEAP) Heap {
+	heap := Heap{
+		Version: uint32(cHeap.dwVersion),
+	}
+
+	segmentCount := int(cHeap.cSegments)
+	heap.Segments = make([]HeapSegmentEntry, segmentCount)
+	if cHeap.pSegments != nil {
+		for i, cEntry := range cArray[C.VMMDLL_MAP_HEAP_SEGMENTENTRY](unsafe.Pointer(cHeap.pSegments), segmentCount) {
+			heap.Segments[i] = newHeapSegmentEntry(cEntry)
+		}
+	}
+
+	count := int(cHeap.cMap)
+	heap.Entries = make([]HeapEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_HEAPENTRY](afterDWORD(unsafe.Pointer(&cHeap.cMap)), count) {
+		heap.Entries[i] = newHeapEntry(cEntry)
+	}
+
+	for _, segment := range heap.Segments {
+		for i := range heap.Entries {
+			if heap.Entries[i].Index == uint32(segment.HeapIndex) {
+				heap.Entries[i].Segments = append(heap.Entries[i].Segments, segment)
+				break
+			}
+		}
+	}
+
+	return heap
+}
+
+func newHeapAllocEntry(cEntry *C.VMMDLL_MAP_HEAPALLOCENTRY) HeapAllocEntry {
+	// VMMDLL_HEAPALLOC_TP tp : 16; DWORD iHeap : 16;
+	bits := *(*uint32)(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.cb), unsafe.Sizeof(cEntry.cb)))
+
+	return HeapAllocEntry{
+		Va:        uint64(cEntry.va),
+		Size:      uint32(cEntry.cb),
+		Type:      HeapAllocType(bits & 0xFFFF),
+		HeapIndex: uint16(bits >> 16),
+	}
+}
+
+func newHeapAlloc(cHeapAlloc *C.VMMDLL_MAP_HEAPALLOC) HeapAlloc {
+	count := int(cHeapAlloc.cMap)
+	entries := make([]HeapAllocEntry, count)
+
+	// pMap is QWORD aligned and follows padding after cMap, it starts at the end of the struct
+	entriesPtr := unsafe.Add(unsafe.Pointer(cHeapAlloc), unsafe.Sizeof(*cHeapAlloc))
+	for i, cEntry := range cArray[C.VMMDLL_MAP_HEAPALLOCENTRY](entriesPtr, count) {
+		entries[i] = newHeapAllocEntry(cEntry)
+	}
+
+	return HeapAlloc{
+		Version: uint32(cHeapAlloc.dwVersion),
+		Entries: entries,
+	}
+}
+
+func (vmm *Vmm) getProcessMapHeap(pid uint32) (*Heap, error) {
+	var cHeapMap C.PVMMDLL_MAP_HEAP
+	success := C.VMMDLL_Map_GetHeap(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), &cHeapMap)
+
+	if success == 0 || cHeapMap == nil {
+		return nil, fmt.Errorf("failed to get heap map for process %d", pid)
+	}
+
+	defer freeMemory(C.PVOID(cHeapMap))
+
+	if cHeapMap.dwVersion != MapHeapVersion {
+		return nil, ErrUnsupportedHeapVersion
+	}
+
+	heap := newHeap(cHeapMap)
+
+	return &heap, nil
+}
+
+func (vmm *Vmm) GetProcessMapHeap(ctx context.Context, pid uint32) (*Heap, error) {
+	resultChan := make(chan struct {
+		heap *Heap
+		err  error
+	}, 1)
+
+	go func() {
+		heap, err := vmm.getProcessMapHeap(pid)
+		resultChan <- struct {
+			heap *Heap
+			err  error
+		}{heap, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.heap, result.err
+	}
+}
+
+func (vmm *Vmm) getProcessMapHeapAlloc(pid uint32, heapNumOrAddress uint64) (*HeapAlloc, error) {
+	var cHeapAllocMap C.PVMMDLL_MAP_HEAPALLOC
+	success := C.VMMDLL_Map_GetHeapAlloc(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), C.QWORD(heapNumOrAddress), &cHeapAllocMap)
+
+	if success == 0 || cHeapAllocMap == nil {
+		return nil, fmt.Errorf("failed to get heap alloc map for process %d, heap 0x%x", pid, heapNumOrAddress)
+	}
+
+	defer freeMemory(C.PVOID(cHeapAllocMap))
+
+	if cHeapAllocMap.dwVersion != MapHeapAllocVersion {
+		return nil, ErrUnsupportedHeapAllocVersion
+	}
+
+	heapAlloc := newHeapAlloc(cHeapAllocMap)
+
+	return &heapAlloc, nil
+}
+
+// GetProcessMapHeapAlloc retrieves the allocations of a heap identified either by its
+// heap number (HeapEntry.HeapNum) or by its base address (HeapEntry.Va).
+func (vmm *Vmm) GetProcessMapHeapAlloc(ctx context.Context, pid uint32, heapNumOrAddress uint64) (*HeapAlloc, error) {
+	resultChan := make(chan struct {
+		heapAlloc *HeapAlloc
+		err       error
+	}, 1)
+
+	go func() {
+		heapAlloc, err := vmm.getProcessMapHeapAlloc(pid, heapNumOrAddress)
+		resultChan <- struct {
+			heapAlloc *HeapAlloc
+			err       error
+		}{heapAlloc, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.heapAlloc, result.err
+	}
+}
+
+// GetVADHeapAlloc retrieves the heap allocations located inside a heap VAD entry.
+func (vmm *Vmm) GetVADHeapAlloc(ctx context.Context, pid uint32, vad *VADEntry) ([]HeapAllocEntry, error) {
+	if vad == nil || !vad.IsHeap {
+		return nil, ErrNotHeapVAD
+	}
+
+	heapAlloc, err := vmm.GetProcessMapHeapAlloc(ctx, pid, uint64(vad.HeapNum))
+	if err != nil {
+		return nil, err
+	}
+
+	return heapAlloc.InRange(vad.VaStart, vad.VaEnd), nil
+}
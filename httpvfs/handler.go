@@ -0,0 +1,208 @@
+package httpvfs
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+	"github.com/sergeyzav/memprocfs/vfs"
+)
+
+// DefaultMaxWriteSize is the default limit of a PUT request body.
+const DefaultMaxWriteSize = 16 << 20
+
+// Handler serves a MemProcFS VFS over HTTP:
+//
+//	GET/HEAD <file>       file contents, supports Range requests
+//	GET/HEAD <directory>  JSON listing, see Listing
+//	PUT <file>[?offset=n] writes the request body at offset, only if AllowWrite is set
+type Handler struct {
+	fs *vfs.FS
+
+	// AllowWrite enables PUT requests.
+	AllowWrite bool
+
+	// MaxWriteSize limits the size of a PUT request body.
+	MaxWriteSize int64
+}
+
+// Listing is the JSON representation of a directory.
+type Listing struct {
+	Path    string         `json:"path"`
+	Entries []ListingEntry `json:"entries"`
+}
+
+type ListingEntry struct {
+	Name           string    `json:"name"`
+	Size           uint64    `json:"size"`
+	IsDir          bool      `json:"dir"`
+	Compressed     bool      `json:"compressed,omitempty"`
+	CreationTime   time.Time `json:"created"`
+	LastAccessTime time.Time `json:"accessed"`
+	LastWriteTime  time.Time `json:"modified"`
+}
+
+func NewHandler(fsys *vfs.FS) *Handler {
+	return &Handler{
+		fs:           fsys,
+		MaxWriteSize: DefaultMaxWriteSize,
+	}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+
+	fsys := h.fs.WithContext(r.Context())
+
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		h.serveGet(w, r, fsys, name)
+	case http.MethodPut:
+		if !h.AllowWrite {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "writes are disabled", http.StatusMethodNotAllowed)
+			return
+		}
+		h.servePut(w, r, fsys, name)
+	default:
+		if h.AllowWrite {
+			w.Header().Set("Allow", "GET, HEAD, PUT")
+		} else {
+			w.Header().Set("Allow", "GET, HEAD")
+		}
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h *Handler) serveGet(w http.ResponseWriter, r *http.Request, fsys *vfs.FS, name string) {
+	file, err := fsys.Open(name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	if info.IsDir() {
+		h.serveDir(w, r, fsys, name)
+		return
+	}
+
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "file is not seekable", http.StatusInternalServerError)
+		return
+	}
+
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+func (h *Handler) serveDir(w http.ResponseWriter, r *http.Request, fsys *vfs.FS, name string) {
+	entries, err := fsys.ReadDir(name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+
+	listing := Listing{
+		Path:    fsys.VfsPath(name),
+		Entries: make([]ListingEntry, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		listing.Entries = append(listing.Entries, newListingEntry(info))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_ = json.NewEncoder(w).Encode(listing)
+}
+
+func (h *Handler) servePut(w http.ResponseWriter, r *http.Request, fsys *vfs.FS, name string) {
+	var offset uint64
+	if value := r.URL.Query().Get("offset"); value != "" {
+		var err error
+		offset, err = strconv.ParseUint(value, 0, 64)
+		if err != nil {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+	}
+
+	file, err := fsys.Open(name)
+	if err != nil {
+		serveError(w, err)
+		return
+	}
+	defer file.Close()
+
+	writer, ok := file.(io.WriterAt)
+	if !ok {
+		http.Error(w, "not a file", http.StatusConflict)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.MaxWriteSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if _, err := writer.WriteAt(data, int64(offset)); err != nil {
+		serveError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newListingEntry(info fs.FileInfo) ListingEntry {
+	entry := ListingEntry{
+		Name:          info.Name(),
+		Size:          uint64(info.Size()),
+		IsDir:         info.IsDir(),
+		LastWriteTime: info.ModTime(),
+	}
+
+	if sys, ok := info.Sys().(memprocfs.VfsEntry); ok {
+		entry.Compressed = sys.Compressed
+		entry.CreationTime = sys.CreationTime
+		entry.LastAccessTime = sys.LastAccessTime
+	}
+
+	return entry
+}
+
+func serveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	case errors.Is(err, fs.ErrInvalid):
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+	case errors.Is(err, fs.ErrPermission):
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
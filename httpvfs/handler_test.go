@@ -0,0 +1,215 @@
+package httpvfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+	"github.com/sergeyzav/memprocfs/vfs"
+)
+
+// fakeBackend is an in-memory VFS holding files by absolute path. Directories are
+// implied by the file paths.
+type fakeBackend struct {
+	files map[string][]byte
+}
+
+func (b *fakeBackend) VfsList(ctx context.Context, dir string) ([]memprocfs.VfsEntry, error) {
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	seen := make(map[string]bool)
+	var entries []memprocfs.VfsEntry
+
+	for name, data := range b.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		base, _, isDir := strings.Cut(rest, "/")
+		if seen[base] {
+			continue
+		}
+		seen[base] = true
+
+		entry := memprocfs.VfsEntry{Name: base, IsDir: isDir}
+		if !isDir {
+			entry.Size = uint64(len(data))
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 && dir != "/" {
+		return nil, fs.ErrNotExist
+	}
+
+	// the VFS doesn't list in name order
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name > entries[j].Name
+	})
+	return entries, nil
+}
+
+func (b *fakeBackend) VfsRead(ctx context.Context, name string, offset uint64, size uint32) ([]byte, error) {
+	data, ok := b.files[name]
+	if !ok {
+		return nil, errors.New("no such file")
+	}
+	if offset >= uint64(len(data)) {
+		return nil, io.EOF
+	}
+	end := offset + uint64(size)
+	if end > uint64(len(data)) {
+		end = uint64(len(data))
+	}
+	return append([]byte(nil), data[offset:end]...), nil
+}
+
+func (b *fakeBackend) VfsWrite(ctx context.Context, name string, data []byte, offset uint64) (uint32, error) {
+	current, ok := b.files[name]
+	if !ok {
+		return 0, errors.New("no such file")
+	}
+	if end := offset + uint64(len(data)); end > uint64(len(current)) {
+		current = append(current, make([]byte, end-uint64(len(current)))...)
+	}
+	copy(current[offset:], data)
+	b.files[name] = current
+	return uint32(len(data)), nil
+}
+
+func newTestHandler() (*Handler, *fakeBackend) {
+	backend := &fakeBackend{files: map[string][]byte{
+		"/sys/version.txt":      []byte("5.8.0"),
+		"/sys/config/flag.txt":  []byte("0"),
+		"/sys/computername.txt": []byte("DESKTOP"),
+	}}
+	return NewHandler(vfs.New(backend)), backend
+}
+
+func TestGetRange(t *testing.T) {
+	handler, _ := newTestHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/sys/version.txt", nil)
+	req.Header.Set("Range", "bytes=2-4")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusPartialContent)
+	}
+	if body := rec.Body.String(); body != "8.0" {
+		t.Errorf("body = %q, want %q", body, "8.0")
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-4/5" {
+		t.Errorf("Content-Range = %q, want %q", got, "bytes 2-4/5")
+	}
+}
+
+func TestGetDirectoryListing(t *testing.T) {
+	handler, _ := newTestHandler()
+
+	req := httptest.NewRequest(http.MethodGet, "/sys", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+
+	var listing Listing
+	if err := json.NewDecoder(rec.Body).Decode(&listing); err != nil {
+		t.Fatal(err)
+	}
+	if listing.Path != "/sys" {
+		t.Errorf("path = %q, want /sys", listing.Path)
+	}
+
+	want := []ListingEntry{
+		{Name: "computername.txt", Size: 7},
+		{Name: "config", IsDir: true},
+		{Name: "version.txt", Size: 5},
+	}
+	if len(listing.Entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", listing.Entries, want)
+	}
+	for i, entry := range listing.Entries {
+		if entry.Name != want[i].Name || entry.Size != want[i].Size || entry.IsDir != want[i].IsDir {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+}
+
+func TestGetNotFound(t *testing.T) {
+	handler, _ := newTestHandler()
+
+	for _, target := range []string{"/sys/missing.txt", "/missing/file.txt"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s status = %d, want %d", target, rec.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestPutDisabled(t *testing.T) {
+	handler, backend := newTestHandler()
+
+	req := httptest.NewRequest(http.MethodPut, "/sys/config/flag.txt", strings.NewReader("1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	if got := rec.Header().Get("Allow"); got != "GET, HEAD" {
+		t.Errorf("Allow = %q, want %q", got, "GET, HEAD")
+	}
+	if got := string(backend.files["/sys/config/flag.txt"]); got != "0" {
+		t.Errorf("file = %q after disabled write, want %q", got, "0")
+	}
+}
+
+func TestPut(t *testing.T) {
+	handler, backend := newTestHandler()
+	handler.AllowWrite = true
+
+	req := httptest.NewRequest(http.MethodPut, "/sys/version.txt?offset=2", strings.NewReader("9"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	if got := string(backend.files["/sys/version.txt"]); got != "5.9.0" {
+		t.Errorf("file = %q, want %q", got, "5.9.0")
+	}
+}
+
+func TestPutTooLarge(t *testing.T) {
+	handler, backend := newTestHandler()
+	handler.AllowWrite = true
+	handler.MaxWriteSize = 4
+
+	req := httptest.NewRequest(http.MethodPut, "/sys/version.txt", strings.NewReader("6.0.0-rc1"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if got := string(backend.files["/sys/version.txt"]); got != "5.8.0" {
+		t.Errorf("file = %q after rejected write, want %q", got, "5.8.0")
+	}
+}
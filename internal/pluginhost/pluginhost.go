@@ -0,0 +1,41 @@
+// Package pluginhost connects the plugin registry of the root package with the C
+// callbacks exported by the nativeplugin package. The root package sets the
+// functions in its init.
+package pluginhost
+
+import "unsafe"
+
+// Registration is a plugin to register with the plugin manager.
+type Registration struct {
+	// ID is passed back to List, IO and Close, it's never 0.
+	ID      uint64
+	Path    string // VFS path name, backslash separated
+	Root    bool
+	Process bool
+}
+
+// Entry is a directory entry added to a VFS file list. Times are FILETIMEs.
+type Entry struct {
+	Name           string
+	Size           uint64
+	IsDir          bool
+	Compressed     bool
+	CreationTime   uint64
+	LastAccessTime uint64
+	LastWriteTime  uint64
+}
+
+var (
+	// Initialize returns the plugins to register for the VMM_HANDLE handle.
+	Initialize func(handle unsafe.Pointer) []Registration
+
+	// List lists path, relative to the plugin directory, of plugin id.
+	List func(id uint64, pid uint32, path string) ([]Entry, bool)
+
+	// IO reads from or writes to path of plugin id. It returns the number of bytes
+	// transferred and the NTSTATUS of the call.
+	IO func(id uint64, write bool, pid uint32, path string, p []byte, offset uint64) (int, uint32)
+
+	// Close notifies plugin id that it's unloaded.
+	Close func(id uint64)
+)
@@ -0,0 +1,524 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unsafe"
+)
+
+// systemPID is the PID of the System process, which holds the kernel module list.
+const systemPID = 4
+
+// IRPMajorFunctionCount is the number of IRP_MJ_* dispatch routines of a driver object.
+const IRPMajorFunctionCount = 28
+
+var irpMajorFunctionNames = [IRPMajorFunctionCount]string{
+	"IRP_MJ_CREATE", "IRP_MJ_CREATE_NAMED_PIPE", "IRP_MJ_CLOSE", "IRP_MJ_READ", "IRP_MJ_WRITE",
+	"IRP_MJ_QUERY_INFORMATION", "IRP_MJ_SET_INFORMATION", "IRP_MJ_QUERY_EA", "IRP_MJ_SET_EA",
+	"IRP_MJ_FLUSH_BUFFERS", "IRP_MJ_QUERY_VOLUME_INFORMATION", "IRP_MJ_SET_VOLUME_INFORMATION",
+	"IRP_MJ_DIRECTORY_CONTROL", "IRP_MJ_FILE_SYSTEM_CONTROL", "IRP_MJ_DEVICE_CONTROL",
+	"IRP_MJ_INTERNAL_DEVICE_CONTROL", "IRP_MJ_SHUTDOWN", "IRP_MJ_LOCK_CONTROL", "IRP_MJ_CLEANUP",
+	"IRP_MJ_CREATE_MAILSLOT", "IRP_MJ_QUERY_SECURITY", "IRP_MJ_SET_SECURITY", "IRP_MJ_POWER",
+	"IRP_MJ_SYSTEM_CONTROL", "IRP_MJ_DEVICE_CHANGE", "IRP_MJ_QUERY_QUOTA", "IRP_MJ_SET_QUOTA", "IRP_MJ_PNP",
+}
+
+// IRPMajorFunctionName returns the IRP_MJ_* name of a major function index.
+func IRPMajorFunctionName(i int) string {
+	if i < 0 || i >= IRPMajorFunctionCount {
+		return "Unknown"
+	}
+	return irpMajorFunctionNames[i]
+}
+
+type KObjectEntry struct {
+	Va         uint64
+	VaParent   uint64
+	VaChildren []uint64
+	Name       string
+	Type       string
+}
+
+type KObject struct {
+	Version   uint32
+	MultiText []string
+	Entries   []KObjectEntry
+}
+
+type KDriverEntry struct {
+	Va             uint64
+	VaDriverStart  uint64
+	DriverSize     uint64
+	VaDeviceObject uint64
+	Name           string
+	Path           string
+	ServiceKeyName string
+	MajorFunction  [IRPMajorFunctionCount]uint64
+}
+
+// InImage reports whether va lies inside the driver image.
+func (e KDriverEntry) InImage(va uint64) bool {
+	return va >= e.VaDriverStart && va < e.VaDriverStart+e.DriverSize
+}
+
+type KDriver struct {
+	Version   uint32
+	MultiText []string
+	Entries   []KDriverEntry
+}
+
+type KDeviceEntry struct {
+	Va                 uint64
+	Depth              uint32
+	DeviceType         uint32
+	DeviceTypeName     string
+	VaDriverObject     uint64
+	VaAttachedDevice   uint64
+	VaFileSystemDevice uint64
+	VolumeInfo         string
+}
+
+type KDevice struct {
+	Version   uint32
+	MultiText []string
+	Entries   []KDeviceEntry
+}
+
+func newKObjectEntry(cEntry *C.VMMDLL_MAP_KOBJECTENTRY) KObjectEntry {
+	entry := KObjectEntry{
+		Va:       uint64(cEntry.va),
+		VaParent: uint64(cEntry.vaParent),
+		Name:     readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.pvaChild), unsafe.Sizeof(cEntry.pvaChild))),
+		Type:     readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.pvaChild), 2*unsafe.Sizeof(cEntry.pvaChild))),
+	}
+
+	count := int(cEntry.cvaChild)
+	entry.VaChildren = make([]uint64, count)
+	if cEntry.pvaChild != nil {
+		for i, va := range cArray[C.QWORD](unsafe.Pointer(cEntry.pvaChild), count) {
+			entry.VaChildren[i] = uint64(*va)
+		}
+	}
+
+	return entry
+}
+
+func newKObject(cKObject *C.VMMDLL_MAP_KOBJECT) KObject {
+	kObject := KObject{
+		Version: uint32(cKObject.dwVersion),
+	}
+
+	if cKObject.pbMultiText != nil && cKObject.cbMultiText > 0 {
+		kObject.MultiText = multiString(C.GoBytes(unsafe.Pointer(cKObject.pbMultiText), C.int(cKObject.cbMultiText)))
+	}
+
+	count := int(cKObject.cMap)
+	kObject.Entries = make([]KObjectEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_KOBJECTENTRY](afterDWORD(unsafe.Pointer(&cKObject.cMap)), count) {
+		kObject.Entries[i] = newKObjectEntry(cEntry)
+	}
+
+	return kObject
+}
+
+func newKDriverEntry(cEntry *C.VMMDLL_MAP_KDRIVERENTRY) KDriverEntry {
+	namePtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.vaDeviceObject), unsafe.Sizeof(cEntry.vaDeviceObject))
+	ptrSize := unsafe.Sizeof(uintptr(0))
+
+	entry := KDriverEntry{
+		Va:             uint64(cEntry.va),
+		VaDriverStart:  uint64(cEntry.vaDriverStart),
+		DriverSize:     uint64(cEntry.cbDriverSize),
+		VaDeviceObject: uint64(cEntry.vaDeviceObject),
+		Name:           readCString(namePtr),
+		Path:           readCString(unsafe.Pointer(uintptr(namePtr) + ptrSize)),
+		ServiceKeyName: readCString(unsafe.Pointer(uintptr(namePtr) + 2*ptrSize)),
+	}
+
+	for i := 0; i < IRPMajorFunctionCount; i++ {
+		entry.MajorFunction[i] = uint64(cEntry.MajorFunction[i])
+	}
+
+	return entry
+}
+
+func newKDriver(cKDriver *C.VMMDLL_MAP_KDRIVER) KDriver {
+	kDriver := KDriver{
+		Version: uint32(cKDriver.dwVersion),
+	}
+
+	if cKDriver.pbMultiText != nil && cKDriver.cbMultiText > 0 {
+		kDriver.MultiText = multiString(C.GoBytes(unsafe.Pointer(cKDriver.pbMultiText), C.int(cKDriver.cbMultiText)))
+	}
+
+	count := int(cKDriver.cMap)
+	kDriver.Entries = make([]KDriverEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_KDRIVERENTRY](afterDWORD(unsafe.Pointer(&cKDriver.cMap)), count) {
+		kDriver.Entries[i] = newKDriverEntry(cEntry)
+	}
+
+	return kDriver
+}
+
+func newKDeviceEntry(cEntry *C.VMMDLL_MAP_KDEVICEENTRY) KDeviceEntry {
+	return KDeviceEntry{
+		Va:                 uint64(cEntry.va),
+		Depth:              uint32(cEntry.iDepth),
+		DeviceType:         uint32(cEntry.dwDeviceType),
+		DeviceTypeName:     readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.dwDeviceType), unsafe.Sizeof(cEntry.dwDeviceType))),
+		VaDriverObject:     uint64(cEntry.vaDriverObject),
+		VaAttachedDevice:   uint64(cEntry.vaAttachedDevice),
+		VaFileSystemDevice: uint64(cEntry.vaFileSystemDevice),
+		VolumeInfo:         readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.vaFileSystemDevice), unsafe.Sizeof(cEntry.vaFileSystemDevice))),
+	}
+}
+
+func newKDevice(cKDevice *C.VMMDLL_MAP_KDEVICE) KDevice {
+	kDevice := KDevice{
+		Version: uint32(cKDevice.dwVersion),
+	}
+
+	if cKDevice.pbMultiText != nil && cKDevice.cbMultiText > 0 {
+		kDevice.MultiText = multiString(C.GoBytes(unsafe.Pointer(cKDevice.pbMultiText), C.int(cKDevice.cbMultiText)))
+	}
+
+	count := int(cKDevice.cMap)
+	kDevice.Entries = make([]KDeviceEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_KDEVICEENTRY](afterDWORD(unsafe.Pointer(&cKDevice.cMap)), count) {
+		kDevice.Entries[i] = newKDeviceEntry(cEntry)
+	}
+
+	return kDevice
+}
+
+func (vmm *Vmm) getKernelObjectMap() (*KObject, error) {
+	var cKObjectMap C.PVMMDLL_MAP_KOBJECT
+	success := C.VMMDLL_Map_GetKObjectU(C.VMM_HANDLE(vmm.handle), &cKObjectMap)
+
+	if success == 0 || cKObjectMap == nil {
+		return nil, fmt.Errorf("failed to get kernel object map")
+	}
+
+	defer freeMemory(C.PVOID(cKObjectMap))
+
+	if cKObjectMap.dwVersion != MapKObjectVersion {
+		return nil, ErrUnsupportedKObjectVersion
+	}
+
+	kObject := newKObject(cKObjectMap)
+
+	return &kObject, nil
+}
+
+func (vmm *Vmm) GetKernelObjectMap(ctx context.Context) (*KObject, error) {
+	resultChan := make(chan struct {
+		kObject *KObject
+		err     error
+	}, 1)
+
+	go func() {
+		kObject, err := vmm.getKernelObjectMap()
+		resultChan <- struct {
+			kObject *KObject
+			err     error
+		}{kObject, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.kObject, result.err
+	}
+}
+
+func (vmm *Vmm) getKernelDriverMap() (*KDriver, error) {
+	var cKDriverMap C.PVMMDLL_MAP_KDRIVER
+	success := C.VMMDLL_Map_GetKDriverU(C.VMM_HANDLE(vmm.handle), &cKDriverMap)
+
+	if success == 0 || cKDriverMap == nil {
+		return nil, fmt.Errorf("failed to get kernel driver map")
+	}
+
+	defer freeMemory(C.PVOID(cKDriverMap))
+
+	if cKDriverMap.dwVersion != MapKDriverVersion {
+		return nil, ErrUnsupportedKDriverVersion
+	}
+
+	kDriver := newKDriver(cKDriverMap)
+
+	return &kDriver, nil
+}
+
+func (vmm *Vmm) GetKernelDriverMap(ctx context.Context) (*KDriver, error) {
+	resultChan := make(chan struct {
+		kDriver *KDriver
+		err     error
+	}, 1)
+
+	go func() {
+		kDriver, err := vmm.getKernelDriverMap()
+		resultChan <- struct {
+			kDriver *KDriver
+			err     error
+		}{kDriver, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.kDriver, result.err
+	}
+}
+
+func (vmm *Vmm) getKernelDeviceMap() (*KDevice, error) {
+	var cKDeviceMap C.PVMMDLL_MAP_KDEVICE
+	success := C.VMMDLL_Map_GetKDeviceU(C.VMM_HANDLE(vmm.handle), &cKDeviceMap)
+
+	if success == 0 || cKDeviceMap == nil {
+		return nil, fmt.Errorf("failed to get kernel device map")
+	}
+
+	defer freeMemory(C.PVOID(cKDeviceMap))
+
+	if cKDeviceMap.dwVersion != MapKDeviceVersion {
+		return nil, ErrUnsupportedKDeviceVersion
+	}
+
+	kDevice := newKDevice(cKDeviceMap)
+
+	return &kDevice, nil
+}
+
+func (vmm *Vmm) GetKernelDeviceMap(ctx context.Context) (*KDevice, error) {
+	resultChan := make(chan struct {
+		kDevice *KDevice
+		err     error
+	}, 1)
+
+	go func() {
+		kDevice, err := vmm.getKernelDeviceMap()
+		resultChan <- struct {
+			kDevice *KDevice
+			err     error
+		}{kDevice, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.kDevice, result.err
+	}
+}
+
+type KObjectNode struct {
+	KObjectEntry
+	Parent   *KObjectNode
+	Children []*KObjectNode
+	// Driver is set when the object is a driver object.
+	Driver *KDriverNode
+	// Device is set when the object is a device object.
+	Device *KDeviceNode
+}
+
+type KDriverNode struct {
+	KDriverEntry
+	Object  *KObjectNode
+	Devices []*KDeviceNode
+}
+
+type KDeviceNode struct {
+	KDeviceEntry
+	Object *KObjectNode
+	Driver *KDriverNode
+	// Attached is the device attached on top of this device, if any.
+	Attached *KDeviceNode
+	// Lower is the device this device is attached to, if any.
+	Lower *KDeviceNode
+}
+
+// Stack returns the device stack from this device to the top-most attached device.
+func (d *KDeviceNode) Stack() []*KDeviceNode {
+	stack := []*KDeviceNode{d}
+	seen := map[*KDeviceNode]bool{d: true}
+	for next := d.Attached; next != nil && !seen[next]; next = next.Attached {
+		stack = append(stack, next)
+		seen[next] = true
+	}
+	return stack
+}
+
+// KernelObjectTree links the kernel object manager directory tree with the driver
+// objects and their device stacks.
+type KernelObjectTree struct {
+	Root    *KObjectNode
+	Objects map[uint64]*KObjectNode
+	Drivers map[uint64]*KDriverNode
+	Devices map[uint64]*KDeviceNode
+	// Kernel is the ntoskrnl.exe image, used to tell dispatch routines of the kernel
+	// itself from hooks. Zero if unknown.
+	Kernel ModuleEntry
+}
+
+// NewKernelObjectTree assembles a tree from the object, driver and device maps.
+func NewKernelObjectTree(objects *KObject, drivers *KDriver, devices *KDevice) *KernelObjectTree {
+	tree := &KernelObjectTree{
+		Objects: make(map[uint64]*KObjectNode, len(objects.Entries)),
+		Drivers: make(map[uint64]*KDriverNode, len(drivers.Entries)),
+		Devices: make(map[uint64]*KDeviceNode, len(devices.Entries)),
+	}
+
+	for _, entry := range objects.Entries {
+		tree.Objects[entry.Va] = &KObjectNode{KObjectEntry: entry}
+	}
+	for _, entry := range drivers.Entries {
+		tree.Drivers[entry.Va] = &KDriverNode{KDriverEntry: entry}
+	}
+	for _, entry := range devices.Entries {
+		tree.Devices[entry.Va] = &KDeviceNode{KDeviceEntry: entry}
+	}
+
+	for _, entry := range objects.Entries {
+		node := tree.Objects[entry.Va]
+		if parent, ok := tree.Objects[entry.VaParent]; ok && parent != node {
+			node.Parent = parent
+		} else if tree.Root == nil {
+			tree.Root = node
+		}
+		for _, va := range entry.VaChildren {
+			if child, ok := tree.Objects[va]; ok {
+				node.Children = append(node.Children, child)
+			}
+		}
+		if driver, ok := tree.Drivers[entry.Va]; ok {
+			node.Driver = driver
+			driver.Object = node
+		}
+		if device, ok := tree.Devices[entry.Va]; ok {
+			node.Device = device
+			device.Object = node
+		}
+	}
+
+	for _, entry := range devices.Entries {
+		device := tree.Devices[entry.Va]
+		if driver, ok := tree.Drivers[entry.VaDriverObject]; ok {
+			device.Driver = driver
+			driver.Devices = append(driver.Devices, device)
+		}
+		if attached, ok := tree.Devices[entry.VaAttachedDevice]; ok && attached != device {
+			device.Attached = attached
+			attached.Lower = device
+		}
+	}
+
+	return tree
+}
+
+func (vmm *Vmm) GetKernelObjectTree(ctx context.Context) (*KernelObjectTree, error) {
+	objects, err := vmm.GetKernelObjectMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	drivers, err := vmm.GetKernelDriverMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := vmm.GetKernelDeviceMap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := vmm.GetProcessModuleList(ctx, systemPID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := NewKernelObjectTree(objects, drivers, devices)
+	for _, module := range modules.Entries {
+		if strings.EqualFold(module.Name, "ntoskrnl.exe") {
+			tree.Kernel = module
+			break
+		}
+	}
+
+	return tree, nil
+}
+
+type IRPHook struct {
+	Driver        *KDriverNode
+	MajorFunction int
+	Target        uint64
+	// TargetDriver is the driver whose image contains Target, nil if none does.
+	TargetDriver *KDriverNode
+}
+
+// DetectIRPHooks reports dispatch routines pointing outside the image of their driver.
+// Targets inside ntoskrnl, e.g. nt!IopInvalidDeviceRequest which fills unhandled major
+// functions, are not reported. Targets inside the image of another known driver have
+// TargetDriver set; these are commonly shared dispatch routines of filter and class
+// drivers, while a nil TargetDriver points to memory not backed by any driver image.
+//
+// Hooks are sorted by driver address and major function.
+func (t *KernelObjectTree) DetectIRPHooks() []IRPHook {
+	var hooks []IRPHook
+	for _, driver := range t.Drivers {
+		for i, target := range driver.MajorFunction {
+			if target == 0 || driver.InImage(target) || t.inKernel(target) {
+				continue
+			}
+			hooks = append(hooks, IRPHook{
+				Driver:        driver,
+				MajorFunction: i,
+				Target:        target,
+				TargetDriver:  t.driverForAddress(target),
+			})
+		}
+	}
+
+	sort.Slice(hooks, func(i, j int) bool {
+		if hooks[i].Driver.Va != hooks[j].Driver.Va {
+			return hooks[i].Driver.Va < hooks[j].Driver.Va
+		}
+		return hooks[i].MajorFunction < hooks[j].MajorFunction
+	})
+
+	return hooks
+}
+
+// OrphanDevices returns the device objects whose driver object is not a known driver.
+func (t *KernelObjectTree) OrphanDevices() []*KDeviceNode {
+	var result []*KDeviceNode
+	for _, device := range t.Devices {
+		if device.Driver == nil {
+			result = append(result, device)
+		}
+	}
+	return result
+}
+
+func (t *KernelObjectTree) inKernel(va uint64) bool {
+	return va >= t.Kernel.VaBase && va < t.Kernel.VaBase+uint64(t.Kernel.ImageSize)
+}
+
+// driverForAddress returns the driver whose image contains va. Overlapping images
+// resolve to the lowest driver object address so the result doesn't depend on map order.
+func (t *KernelObjectTree) driverForAddress(va uint64) *KDriverNode {
+	var result *KDriverNode
+	for _, driver := range t.Drivers {
+		if driver.InImage(va) && (result == nil || driver.Va < result.Va) {
+			result = driver
+		}
+	}
+	return result
+}
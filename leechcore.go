@@ -1,10 +1,13 @@
 package go_memprocfs
 
 /*
+#include <stdlib.h>
 #include "leechcore.h"
 */
 import "C"
 import (
+	"errors"
+	"sync"
 	"unicode/utf16"
 	"unsafe"
 )
@@ -13,6 +16,7 @@ const (
 	MemScatterVersion     = 0xc0fe0002
 	MemScatterStackSize   = 12
 	MemScatterAddrInvalid = ^uint64(0) // ((QWORD)-1)
+	pageSize              = 0x1000
 )
 
 type LCConfigErrorInfo struct {
@@ -57,34 +61,126 @@ func newLCConfigErrorInfo(pInfo C.PLC_CONFIG_ERRORINFO) *LCConfigErrorInfo {
 	return info
 }
 
-//type MemScatter struct {
-//	cMem C.MEM_SCATTER
-//	buf  []byte // to keep reference to Go-side buffer, so GC doesn’t move it
-//}
-//
-//func NewMemScatter(addr uint64, size uint32) *MemScatter {
-//	ms := &MemScatter{
-//		buf: make([]byte, size),
-//	}
-//	ms.cMem.version = C.DWORD(MemScatterVersion)
-//	ms.cMem.f = 0
-//	ms.cMem.qwA = C.QWORD(addr)
-//	ms.cMem.pb = (*C.uchar)(unsafe.Pointer(&ms.buf[0]))
-//	ms.cMem.cb = C.DWORD(size)
-//	ms.cMem.iStack = 0
-//	for i := 0; i < MemScatterStackSize; i++ {
-//		ms.cMem.vStack[i] = 0
-//	}
-//	return ms
-//}
-//
-//func (m *MemScatter) Data() []byte {
-//	if m.cMem.f == 1 {
-//		return m.buf
-//	}
-//	return nil
-//}
-//
-//func (m *MemScatter) Success() bool {
-//	return m.cMem.f == 1
-//}
+// MemScatter is a single unit of work of MemReadScatter/MemWriteScatter. The
+// MEM_SCATTER and its buffer are allocated in C memory, so no Go pointers are passed
+// to C. It must be released with Free.
+type MemScatter struct {
+	mu   sync.RWMutex // held for reading while a scatter call uses the C memory
+	cMem *C.MEM_SCATTER
+	pb   unsafe.Pointer
+}
+
+var (
+	ErrMemScatterSize  = errors.New("scatter size must be 1-4096 bytes and must not cross a page boundary")
+	ErrMemScatterFree  = errors.New("scatter already freed")
+	ErrMemScatterAlloc = errors.New("failed to allocate scatter")
+)
+
+// NewMemScatter allocates a scatter for size bytes at addr. size is at most one 4k
+// page and the range must not cross a page boundary.
+func NewMemScatter(addr uint64, size uint32) (*MemScatter, error) {
+	if size == 0 || size > pageSize || addr&(pageSize-1)+uint64(size) > pageSize {
+		return nil, ErrMemScatterSize
+	}
+
+	cMem := (*C.MEM_SCATTER)(C.calloc(1, C.size_t(unsafe.Sizeof(C.MEM_SCATTER{}))))
+	pb := C.calloc(1, C.size_t(size))
+	if cMem == nil || pb == nil {
+		// free(NULL) is a no-op
+		C.free(unsafe.Pointer(cMem))
+		C.free(pb)
+		return nil, ErrMemScatterAlloc
+	}
+
+	cMem.version = C.DWORD(MemScatterVersion)
+	cMem.qwA = C.QWORD(addr)
+	cMem.cb = C.DWORD(size)
+	// pb is hidden in an anonymous union
+	*(*unsafe.Pointer)(afterField(unsafe.Pointer(cMem), unsafe.Offsetof(cMem.qwA), unsafe.Sizeof(cMem.qwA))) = pb
+
+	return &MemScatter{cMem: cMem, pb: pb}, nil
+}
+
+func (m *MemScatter) Address() uint64 {
+	return uint64(m.cMem.qwA)
+}
+
+func (m *MemScatter) Size() uint32 {
+	return uint32(m.cMem.cb)
+}
+
+// Success reports whether the last scatter call succeeded for this entry.
+func (m *MemScatter) Success() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cMem != nil && m.cMem.f != 0
+}
+
+// Data returns a copy of the buffer if the last scatter call succeeded.
+func (m *MemScatter) Data() []byte {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.cMem == nil || m.cMem.f == 0 {
+		return nil
+	}
+	return C.GoBytes(m.pb, C.int(m.cMem.cb))
+}
+
+// SetData copies data to be written by MemWriteScatter into the buffer, it's
+// truncated to Size bytes.
+func (m *MemScatter) SetData(data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cMem == nil {
+		return ErrMemScatterFree
+	}
+	copy(unsafe.Slice((*byte)(m.pb), int(m.cMem.cb)), data)
+	return nil
+}
+
+// Free releases the C memory. It waits for scatter calls still using it, e.g.
+// after their context was cancelled.
+func (m *MemScatter) Free() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cMem == nil {
+		return
+	}
+	C.free(m.pb)
+	C.free(unsafe.Pointer(m.cMem))
+	m.cMem = nil
+	m.pb = nil
+}
+
+// acquireMemScatters locks scatters for a scatter call and resets their status, since
+// entries already marked as successful are skipped by MemProcFS. The returned array
+// holds C pointers only. release must be called once the call has returned.
+func acquireMemScatters(scatters []*MemScatter) (ppMEMs []*C.MEM_SCATTER, release func(), err error) {
+	ppMEMs = make([]*C.MEM_SCATTER, 0, len(scatters))
+	release = func() {
+		for _, m := range scatters[:len(ppMEMs)] {
+			m.mu.RUnlock()
+		}
+	}
+
+	for _, m := range scatters {
+		m.mu.RLock()
+		if m.cMem == nil {
+			m.mu.RUnlock()
+			release()
+			return nil, nil, ErrMemScatterFree
+		}
+		m.cMem.f = 0
+		ppMEMs = append(ppMEMs, m.cMem)
+	}
+
+	return ppMEMs, release, nil
+}
+
+func memScatterResults(scatters []*MemScatter) []bool {
+	result := make([]bool, len(scatters))
+	for i, m := range scatters {
+		result[i] = m.cMem.f != 0
+	}
+	return result
+}
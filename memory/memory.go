@@ -7,13 +7,12 @@ import (
 	"math"
 	"sync"
 	"time"
-	"unsafe"
 )
 
 type unit struct {
 	address uint64
 	size    uint32
-	buffer  []byte
+	result  *go_memprocfs.ScatterResult
 	resChan chan<- []byte
 	timer   *time.Timer
 }
@@ -43,15 +42,16 @@ func (m *Memory) Read(ctx context.Context, address uint64, size uint32, tte time
 		address: address,
 		size:    size,
 		resChan: result,
-		buffer:  make([]byte, size),
 	}
 
-	err := m.scatterTask.PrepareRead(ctx, address, size, unsafe.Pointer(&task.buffer[0]))
+	scatterResult, err := m.scatterTask.PrepareRead(ctx, address, size)
 
 	if err != nil {
 		return nil, err
 	}
 
+	task.result = scatterResult
+
 	task.timer = time.AfterFunc(tte, func() {
 		m.ReadExecute(ctx)
 	})
@@ -254,7 +254,7 @@ func (m *Memory) ReadExecute(ctx context.Context) error {
 
 	for _, u := range m.units {
 		u.timer.Stop()
-		u.resChan <- u.buffer
+		u.resChan <- u.result.Bytes()
 		close(u.resChan)
 	}
 
@@ -0,0 +1,37 @@
+package nativeplugin
+
+// The exported functions live in their own file since cgo doesn't allow C
+// definitions in the preamble of a file using //export.
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import "github.com/sergeyzav/memprocfs/internal/pluginhost"
+
+//export InitializeVmmPlugin
+func InitializeVmmPlugin(H C.VMM_HANDLE, pRegInfo C.PVMMDLL_PLUGIN_REGINFO) {
+	initialize(H, pRegInfo)
+}
+
+//export goPluginList
+func goPluginList(H C.VMM_HANDLE, ctxP C.PVMMDLL_PLUGIN_CONTEXT, pFileList C.PHANDLE) C.BOOL {
+	return list(ctxP, pFileList)
+}
+
+//export goPluginRead
+func goPluginRead(H C.VMM_HANDLE, ctxP C.PVMMDLL_PLUGIN_CONTEXT, pb C.PBYTE, cb C.DWORD, pcbRead C.PDWORD, cbOffset C.ULONG64) C.NTSTATUS {
+	return transfer(ctxP, false, pb, cb, pcbRead, cbOffset)
+}
+
+//export goPluginWrite
+func goPluginWrite(H C.VMM_HANDLE, ctxP C.PVMMDLL_PLUGIN_CONTEXT, pb C.PBYTE, cb C.DWORD, pcbWrite C.PDWORD, cbOffset C.ULONG64) C.NTSTATUS {
+	return transfer(ctxP, true, pb, cb, pcbWrite, cbOffset)
+}
+
+//export goPluginClose
+func goPluginClose(H C.VMM_HANDLE, ctxP C.PVMMDLL_PLUGIN_CONTEXT) {
+	if id := contextId(ctxP); id != 0 {
+		pluginhost.Close(id)
+	}
+}
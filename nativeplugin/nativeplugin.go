@@ -0,0 +1,145 @@
+// Package nativeplugin exports the C entry points of a MemProcFS native plugin for
+// the plugins registered with memprocfs.RegisterPlugin. Import it only from a program
+// built with -buildmode=c-shared:
+//
+//	import _ "github.com/sergeyzav/memprocfs/nativeplugin"
+//
+// Any program importing it exports InitializeVmmPlugin and the plugin callbacks.
+package nativeplugin
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+#include "vmmdll.h"
+
+extern BOOL goPluginList(VMM_HANDLE H, PVMMDLL_PLUGIN_CONTEXT ctxP, PHANDLE pFileList);
+extern NTSTATUS goPluginRead(VMM_HANDLE H, PVMMDLL_PLUGIN_CONTEXT ctxP, PBYTE pb, DWORD cb, PDWORD pcbRead, ULONG64 cbOffset);
+extern NTSTATUS goPluginWrite(VMM_HANDLE H, PVMMDLL_PLUGIN_CONTEXT ctxP, PBYTE pb, DWORD cb, PDWORD pcbWrite, ULONG64 cbOffset);
+extern VOID goPluginClose(VMM_HANDLE H, PVMMDLL_PLUGIN_CONTEXT ctxP);
+
+static BOOL pluginRegInfoValid(PVMMDLL_PLUGIN_REGINFO pRegInfo) {
+	return pRegInfo->magic == VMMDLL_PLUGIN_REGINFO_MAGIC && pRegInfo->wVersion == VMMDLL_PLUGIN_REGINFO_VERSION;
+}
+
+static BOOL pluginRegister(VMM_HANDLE H, PVMMDLL_PLUGIN_REGINFO pRegInfo, ULONG64 id, LPSTR uszPathName, BOOL fRoot, BOOL fProcess) {
+	memset(&pRegInfo->reg_info, 0, sizeof(pRegInfo->reg_info));
+	memset(&pRegInfo->reg_fn, 0, sizeof(pRegInfo->reg_fn));
+	pRegInfo->reg_info.ctxM = (PVMMDLL_PLUGIN_INTERNAL_CONTEXT)(uintptr_t)id;
+	strncpy(pRegInfo->reg_info.uszPathName, uszPathName, sizeof(pRegInfo->reg_info.uszPathName) - 1);
+	pRegInfo->reg_info.fRootModule = fRoot;
+	pRegInfo->reg_info.fProcessModule = fProcess;
+	pRegInfo->reg_fn.pfnList = goPluginList;
+	pRegInfo->reg_fn.pfnRead = goPluginRead;
+	pRegInfo->reg_fn.pfnWrite = goPluginWrite;
+	pRegInfo->reg_fn.pfnClose = goPluginClose;
+	return pRegInfo->pfnPluginManager_Register(H, pRegInfo);
+}
+
+static ULONG64 pluginContextId(PVMMDLL_PLUGIN_CONTEXT ctxP) {
+	return (ULONG64)(uintptr_t)ctxP->ctxM;
+}
+
+static void pluginAddEntry(HANDLE pFileList, LPSTR uszName, BOOL fDirectory, ULONG64 cb, BOOL fCompressed, QWORD ftCreation, QWORD ftLastAccess, QWORD ftLastWrite) {
+	VMMDLL_VFS_FILELIST_EXINFO exInfo = { 0 };
+	exInfo.dwVersion = VMMDLL_VFS_FILELIST_EXINFO_VERSION;
+	exInfo.fCompressed = fCompressed;
+	exInfo.qwCreationTime = ftCreation;
+	exInfo.qwLastAccessTime = ftLastAccess;
+	exInfo.qwLastWriteTime = ftLastWrite;
+	if (fDirectory) {
+		VMMDLL_VfsList_AddDirectory(pFileList, uszName, &exInfo);
+	} else {
+		VMMDLL_VfsList_AddFile(pFileList, uszName, cb, &exInfo);
+	}
+}
+*/
+import "C"
+import (
+	"unsafe"
+
+	// memprocfs sets the pluginhost functions in its init
+	memprocfs "github.com/sergeyzav/memprocfs"
+	"github.com/sergeyzav/memprocfs/internal/pluginhost"
+)
+
+func boolToBOOL(b bool) C.BOOL {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func contextId(ctxP C.PVMMDLL_PLUGIN_CONTEXT) uint64 {
+	if ctxP == nil {
+		return 0
+	}
+	return uint64(C.pluginContextId(ctxP))
+}
+
+func contextPath(ctxP C.PVMMDLL_PLUGIN_CONTEXT) string {
+	if ctxP.uszPath == nil {
+		return ""
+	}
+	return C.GoString(ctxP.uszPath)
+}
+
+func initialize(h C.VMM_HANDLE, pRegInfo C.PVMMDLL_PLUGIN_REGINFO) {
+	if pRegInfo == nil || C.pluginRegInfoValid(pRegInfo) == 0 {
+		return
+	}
+
+	for _, registration := range pluginhost.Initialize(unsafe.Pointer(h)) {
+		cPath := C.CString(registration.Path)
+		C.pluginRegister(h, pRegInfo, C.ULONG64(registration.ID), cPath,
+			boolToBOOL(registration.Root),
+			boolToBOOL(registration.Process))
+		C.free(unsafe.Pointer(cPath))
+	}
+}
+
+func list(ctxP C.PVMMDLL_PLUGIN_CONTEXT, pFileList C.PHANDLE) C.BOOL {
+	id := contextId(ctxP)
+	if id == 0 || pFileList == nil || C.VMMDLL_VfsList_IsHandleValid(C.HANDLE(pFileList)) == 0 {
+		return 0
+	}
+
+	entries, ok := pluginhost.List(id, uint32(ctxP.dwPID), contextPath(ctxP))
+	if !ok {
+		return 0
+	}
+
+	for _, entry := range entries {
+		cName := C.CString(entry.Name)
+		C.pluginAddEntry(C.HANDLE(pFileList), cName,
+			boolToBOOL(entry.IsDir),
+			C.ULONG64(entry.Size),
+			boolToBOOL(entry.Compressed),
+			C.QWORD(entry.CreationTime),
+			C.QWORD(entry.LastAccessTime),
+			C.QWORD(entry.LastWriteTime))
+		C.free(unsafe.Pointer(cName))
+	}
+
+	return 1
+}
+
+func transfer(ctxP C.PVMMDLL_PLUGIN_CONTEXT, write bool, pb C.PBYTE, cb C.DWORD, pcb C.PDWORD, cbOffset C.ULONG64) C.NTSTATUS {
+	if pcb != nil {
+		*pcb = 0
+	}
+
+	id := contextId(ctxP)
+	if id == 0 || pcb == nil {
+		return C.NTSTATUS(memprocfs.VfsStatusFileInvalid)
+	}
+
+	var buffer []byte
+	if pb != nil && cb > 0 {
+		buffer = unsafe.Slice((*byte)(unsafe.Pointer(pb)), int(cb))
+	}
+
+	n, status := pluginhost.IO(id, write, uint32(ctxP.dwPID), contextPath(ctxP), buffer, uint64(cbOffset))
+	*pcb = C.DWORD(n)
+	return C.NTSTATUS(status)
+}
@@ -0,0 +1,234 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+	"unsafe"
+)
+
+// Windows address families
+const (
+	AddressFamilyInet  = 2
+	AddressFamilyInet6 = 23
+)
+
+type NetProtocol uint8
+
+const (
+	NetProtocolTCP NetProtocol = iota
+	NetProtocolUDP
+)
+
+func (p NetProtocol) String() string {
+	switch p {
+	case NetProtocolTCP:
+		return "TCP"
+	case NetProtocolUDP:
+		return "UDP"
+	default:
+		return "Unknown"
+	}
+}
+
+type NetState uint32
+
+const (
+	NetStateClosed      NetState = 0
+	NetStateListening   NetState = 1
+	NetStateSynSent     NetState = 2
+	NetStateSynReceived NetState = 3
+	NetStateEstablished NetState = 4
+	NetStateFinWait1    NetState = 5
+	NetStateFinWait2    NetState = 6
+	NetStateCloseWait   NetState = 7
+	NetStateClosing     NetState = 8
+	NetStateLastAck     NetState = 9
+	NetStateTimeWait    NetState = 12
+)
+
+func (s NetState) String() string {
+	switch s {
+	case NetStateClosed:
+		return "CLOSED"
+	case NetStateListening:
+		return "LISTENING"
+	case NetStateSynSent:
+		return "SYN_SENT"
+	case NetStateSynReceived:
+		return "SYN_RCVD"
+	case NetStateEstablished:
+		return "ESTABLISHED"
+	case NetStateFinWait1:
+		return "FIN_WAIT_1"
+	case NetStateFinWait2:
+		return "FIN_WAIT_2"
+	case NetStateCloseWait:
+		return "CLOSE_WAIT"
+	case NetStateClosing:
+		return "CLOSING"
+	case NetStateLastAck:
+		return "LAST_ACK"
+	case NetStateTimeWait:
+		return "TIME_WAIT"
+	default:
+		return "Unknown"
+	}
+}
+
+type NetEndpoint struct {
+	Valid bool
+	IP    net.IP
+	Port  uint16
+	Text  string
+}
+
+// String returns the endpoint as host:port.
+func (e NetEndpoint) String() string {
+	if !e.Valid {
+		return ""
+	}
+	return net.JoinHostPort(e.IP.String(), fmt.Sprint(e.Port))
+}
+
+type NetEntry struct {
+	PID           uint32
+	State         NetState
+	AddressFamily uint16
+	Protocol      NetProtocol
+	Src           NetEndpoint
+	Dst           NetEndpoint
+	VaObj         uint64
+	CreateTime    time.Time
+	PoolTag       string
+	Text          string
+}
+
+type Net struct {
+	Version   uint32
+	MultiText []string
+	Entries   []NetEntry
+}
+
+// ByPID groups the connections by owning process.
+func (n *Net) ByPID() map[uint32][]NetEntry {
+	result := make(map[uint32][]NetEntry)
+	for _, entry := range n.Entries {
+		result[entry.PID] = append(result[entry.PID], entry)
+	}
+	return result
+}
+
+// netEntryAddr mirrors the anonymous Src/Dst struct of VMMDLL_MAP_NETENTRY.
+type netEntryAddr struct {
+	valid    uint32
+	reserved uint16
+	port     uint16
+	addr     [16]byte
+	text     *C.char
+}
+
+func newNetEndpoint(ptr unsafe.Pointer, af uint16) NetEndpoint {
+	cAddr := (*netEntryAddr)(ptr)
+
+	endpoint := NetEndpoint{
+		Valid: cAddr.valid != 0,
+		Port:  cAddr.port,
+		Text:  C.GoString(cAddr.text),
+	}
+
+	if af == AddressFamilyInet6 {
+		endpoint.IP = net.IP(append([]byte(nil), cAddr.addr[:]...))
+	} else {
+		endpoint.IP = net.IPv4(cAddr.addr[0], cAddr.addr[1], cAddr.addr[2], cAddr.addr[3])
+	}
+
+	return endpoint
+}
+
+func newNetEntry(cEntry *C.VMMDLL_MAP_NETENTRY) NetEntry {
+	af := uint16(cEntry.AF)
+
+	entry := NetEntry{
+		PID:           uint32(cEntry.dwPID),
+		State:         NetState(cEntry.dwState),
+		AddressFamily: af,
+		Src:           newNetEndpoint(unsafe.Pointer(&cEntry.Src), af),
+		Dst:           newNetEndpoint(unsafe.Pointer(&cEntry.Dst), af),
+		VaObj:         uint64(cEntry.vaObj),
+		CreateTime:    fileTimeToTime(uint64(cEntry.ftTime)),
+		PoolTag:       poolTagString(uint32(cEntry.dwPoolTag)),
+		Text:          readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry._FutureUse4), unsafe.Sizeof(cEntry._FutureUse4))),
+	}
+
+	// UDP endpoints are allocated with the 'UdpA' pool tag, TCP ones with 'TcpE'/'TcpL'/'TTcb'.
+	if entry.PoolTag == "UdpA" {
+		entry.Protocol = NetProtocolUDP
+	}
+
+	return entry
+}
+
+func newNet(cNet *C.VMMDLL_MAP_NET) Net {
+	netMap := Net{
+		Version: uint32(cNet.dwVersion),
+	}
+
+	if cNet.pbMultiText != nil && cNet.cbMultiText > 0 {
+		netMap.MultiText = multiString(C.GoBytes(unsafe.Pointer(cNet.pbMultiText), C.int(cNet.cbMultiText)))
+	}
+
+	count := int(cNet.cMap)
+	netMap.Entries = make([]NetEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_NETENTRY](afterDWORD(unsafe.Pointer(&cNet.cMap)), count) {
+		netMap.Entries[i] = newNetEntry(cEntry)
+	}
+
+	return netMap
+}
+
+func (vmm *Vmm) getNetMap() (*Net, error) {
+	var cNetMap C.PVMMDLL_MAP_NET
+	success := C.VMMDLL_Map_GetNetU(C.VMM_HANDLE(vmm.handle), &cNetMap)
+
+	if success == 0 || cNetMap == nil {
+		return nil, fmt.Errorf("failed to get net map")
+	}
+
+	defer freeMemory(C.PVOID(cNetMap))
+
+	if cNetMap.dwVersion != MapNetVersion {
+		return nil, ErrUnsupportedNetVersion
+	}
+
+	netMap := newNet(cNetMap)
+
+	return &netMap, nil
+}
+
+func (vmm *Vmm) GetNetMap(ctx context.Context) (*Net, error) {
+	resultChan := make(chan struct {
+		net *Net
+		err error
+	}, 1)
+
+	go func() {
+		netMap, err := vmm.getNetMap()
+		resultChan <- struct {
+			net *Net
+			err error
+		}{netMap, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.net, result.err
+	}
+}
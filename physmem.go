@@ -0,0 +1,173 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+type PhysMemEntry struct {
+	Pa   uint64
+	Size uint64
+	// Remap is the address of the range in the memory source, equal to Pa unless
+	// the range is remapped by a -memmap file.
+	Remap uint64
+}
+
+type PhysMemMap struct {
+	Version uint32
+	Entries []PhysMemEntry
+}
+
+// WriteMemMapFile writes the map in the text format accepted by the -memmap startup
+// option (and produced by MemProcFS in /sys/memory/physmemmap.txt):
+//
+//	0000      1000 -    9efff ->      1000
+func (p *PhysMemMap) WriteMemMapFile(w io.Writer) error {
+	line := 0
+	for _, entry := range p.Entries {
+		if entry.Size == 0 {
+			continue
+		}
+		_, err := fmt.Fprintf(w, "%04x %12x - %12x -> %12x\n", line, entry.Pa, entry.Pa+entry.Size-1, entry.Remap)
+		if err != nil {
+			return err
+		}
+		line++
+	}
+	return nil
+}
+
+// ParseMemMapFile parses a -memmap text file. Each non-empty line holds an optional
+// hex index, an inclusive hex range "start - end" and an optional "-> remap" column
+// which defaults to start. Lines starting with '#' are ignored.
+func ParseMemMapFile(r io.Reader) (*PhysMemMap, error) {
+	result := &PhysMemMap{Version: MapPhysMemVersion}
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		dash := -1
+		for i, field := range fields {
+			if field == "-" {
+				dash = i
+				break
+			}
+		}
+		if dash < 1 || dash+1 >= len(fields) {
+			return nil, fmt.Errorf("memmap line %d: expected 'start - end'", line)
+		}
+
+		start, err := parseMemMapHex(fields[dash-1])
+		if err != nil {
+			return nil, fmt.Errorf("memmap line %d: %w", line, err)
+		}
+		end, err := parseMemMapHex(fields[dash+1])
+		if err != nil {
+			return nil, fmt.Errorf("memmap line %d: %w", line, err)
+		}
+		if end < start {
+			return nil, fmt.Errorf("memmap line %d: end 0x%x below start 0x%x", line, end, start)
+		}
+
+		remap := start
+		rest := fields[dash+2:]
+		if len(rest) > 0 {
+			if len(rest) != 2 || rest[0] != "->" {
+				return nil, fmt.Errorf("memmap line %d: unexpected trailing data", line)
+			}
+			remap, err = parseMemMapHex(rest[1])
+			if err != nil {
+				return nil, fmt.Errorf("memmap line %d: %w", line, err)
+			}
+		}
+
+		result.Entries = append(result.Entries, PhysMemEntry{Pa: start, Size: end - start + 1, Remap: remap})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseMemMapHex(s string) (uint64, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "0x"), "0X")
+	return strconv.ParseUint(s, 16, 64)
+}
+
+func newPhysMemMap(cPhysMem *C.VMMDLL_MAP_PHYSMEM) PhysMemMap {
+	count := int(cPhysMem.cMap)
+	entries := make([]PhysMemEntry, count)
+
+	entriesPtr := afterField(unsafe.Pointer(cPhysMem), unsafe.Offsetof(cPhysMem._Reserved2), unsafe.Sizeof(cPhysMem._Reserved2))
+	for i, cEntry := range cArray[C.VMMDLL_MAP_PHYSMEMENTRY](entriesPtr, count) {
+		entries[i] = PhysMemEntry{
+			Pa:    uint64(cEntry.pa),
+			Size:  uint64(cEntry.cb),
+			Remap: uint64(cEntry.pa),
+		}
+	}
+
+	return PhysMemMap{
+		Version: uint32(cPhysMem.dwVersion),
+		Entries: entries,
+	}
+}
+
+func (vmm *Vmm) getPhysMemMap() (*PhysMemMap, error) {
+	var cPhysMemMap C.PVMMDLL_MAP_PHYSMEM
+	success := C.VMMDLL_Map_GetPhysMem(C.VMM_HANDLE(vmm.handle), &cPhysMemMap)
+
+	if success == 0 || cPhysMemMap == nil {
+		return nil, fmt.Errorf("failed to get physical memory map")
+	}
+
+	defer freeMemory(C.PVOID(cPhysMemMap))
+
+	if cPhysMemMap.dwVersion != MapPhysMemVersion {
+		return nil, ErrUnsupportedPhysMemVersion
+	}
+
+	physMem := newPhysMemMap(cPhysMemMap)
+
+	return &physMem, nil
+}
+
+func (vmm *Vmm) GetPhysMemMap(ctx context.Context) (*PhysMemMap, error) {
+	resultChan := make(chan struct {
+		physMem *PhysMemMap
+		err     error
+	}, 1)
+
+	go func() {
+		physMem, err := vmm.getPhysMemMap()
+		resultChan <- struct {
+			physMem *PhysMemMap
+			err     error
+		}{physMem, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.physMem, result.err
+	}
+}
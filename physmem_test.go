@@ -0,0 +1,100 @@
+package go_memprocfs
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemMapFileRoundTrip(t *testing.T) {
+	physMem := &PhysMemMap{
+		Version: MapPhysMemVersion,
+		Entries: []PhysMemEntry{
+			{Pa: 0x1000, Size: 0x9e000, Remap: 0x1000},
+			{Pa: 0x100000, Size: 0x3ff00000, Remap: 0x100000},
+			{Pa: 0x100000000, Size: 0x240000000, Remap: 0x40000000},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := physMem.WriteMemMapFile(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := ParseMemMapFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, physMem) {
+		t.Errorf("round trip = %+v, want %+v", parsed, physMem)
+	}
+}
+
+func TestWriteMemMapFileSkipsEmpty(t *testing.T) {
+	physMem := &PhysMemMap{Entries: []PhysMemEntry{
+		{Pa: 0x1000, Size: 0, Remap: 0x1000},
+		{Pa: 0x2000, Size: 0x1000, Remap: 0x2000},
+		{Pa: 0x4000, Size: 0, Remap: 0x4000},
+		{Pa: 0x5000, Size: 0x1000, Remap: 0x9000},
+	}}
+
+	var buf bytes.Buffer
+	if err := physMem.WriteMemMapFile(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "0000         2000 -         2fff ->         2000\n" +
+		"0001         5000 -         5fff ->         9000\n"
+	if buf.String() != want {
+		t.Errorf("WriteMemMapFile = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestParseMemMapFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []PhysMemEntry
+		wantErr bool
+	}{
+		{
+			name: "without index and remap",
+			text: "1000 - 1fff\n0x3000 - 0x3fff\n",
+			want: []PhysMemEntry{{Pa: 0x1000, Size: 0x1000, Remap: 0x1000}, {Pa: 0x3000, Size: 0x1000, Remap: 0x3000}},
+		},
+		{
+			name: "comments and blank lines",
+			text: "# physical memory\n\n0000 1000 - 1fff -> 1000\n",
+			want: []PhysMemEntry{{Pa: 0x1000, Size: 0x1000, Remap: 0x1000}},
+		},
+		{
+			name: "remapped range",
+			text: "0000 100000000 - 13fffffff -> 40000000\n",
+			want: []PhysMemEntry{{Pa: 0x100000000, Size: 0x40000000, Remap: 0x40000000}},
+		},
+		{name: "missing end", text: "1000 -\n", wantErr: true},
+		{name: "end below start", text: "2000 - 1000\n", wantErr: true},
+		{name: "invalid remap", text: "1000 - 1fff -> xyz\n", wantErr: true},
+		{name: "trailing data", text: "1000 - 1fff foo\n", wantErr: true},
+		{name: "invalid hex", text: "xyz - 1fff\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseMemMapFile(strings.NewReader(tt.text))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseMemMapFile succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Entries, tt.want) {
+				t.Errorf("entries = %+v, want %+v", got.Entries, tt.want)
+			}
+		})
+	}
+}
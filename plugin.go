@@ -0,0 +1,250 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/sergeyzav/memprocfs/internal/pluginhost"
+)
+
+// PluginRootPID is the PID passed to a Plugin when it's called as a root module.
+const PluginRootPID = ^uint32(0)
+
+// PluginFlag selects where a plugin is mounted in the VFS.
+type PluginFlag uint32
+
+const (
+	// PluginRootModule mounts the plugin as /<name>.
+	PluginRootModule PluginFlag = 0x1
+
+	// PluginProcessModule mounts the plugin as /pid/<pid>/<name> in every process directory.
+	PluginProcessModule PluginFlag = 0x2
+)
+
+var (
+	ErrPluginName       = errors.New("invalid plugin name")
+	ErrPluginFlags      = errors.New("plugin must be a root and/or process module")
+	ErrPluginRegistered = errors.New("plugin already registered")
+)
+
+// Plugin is a VFS module implemented in Go.
+//
+// Paths are relative to the plugin directory and slash separated, the plugin
+// directory itself is "". The pid is PluginRootPID for root modules.
+//
+// Read returns io.EOF when offset is at or beyond the end of the file. Write is
+// called for writes to plugin files; read-only plugins should return an error.
+type Plugin interface {
+	List(ctx context.Context, pid uint32, path string) ([]VfsEntry, error)
+	Read(ctx context.Context, pid uint32, path string, p []byte, offset uint64) (int, error)
+	Write(ctx context.Context, pid uint32, path string, p []byte, offset uint64) (int, error)
+}
+
+// PluginInitializer is implemented by plugins that need the Vmm before they are
+// registered with the plugin manager. Returning an error skips the registration.
+//
+// The Vmm is owned by the host process and must not be closed by the plugin.
+type PluginInitializer interface {
+	Initialize(v *Vmm) error
+}
+
+// PluginCloser is implemented by plugins that want to be notified when the
+// plugin manager unloads them.
+type PluginCloser interface {
+	Close()
+}
+
+type pluginRegistration struct {
+	name   string
+	plugin Plugin
+	flags  PluginFlag
+}
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   []*pluginRegistration
+)
+
+// RegisterPlugin registers plugin under name. It's meant to be called from an
+// init function of a program built as a MemProcFS native plugin. The program must
+// import the nativeplugin package, which exports the C entry points:
+//
+//	import _ "github.com/sergeyzav/memprocfs/nativeplugin"
+//
+//	go build -buildmode=c-shared -o m_example.so   (m_example.dll on Windows)
+//
+// The library must be placed in the plugins directory next to vmm.so/vmm.dll.
+// MemProcFS then calls the exported InitializeVmmPlugin which registers every
+// plugin registered at that point.
+func RegisterPlugin(name string, plugin Plugin, flags PluginFlag) error {
+	name = strings.Trim(name, "/")
+	if name == "" || len(name) >= 128 {
+		return ErrPluginName
+	}
+	if flags&(PluginRootModule|PluginProcessModule) == 0 {
+		return ErrPluginFlags
+	}
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	for _, registration := range plugins {
+		if strings.EqualFold(registration.name, name) {
+			return ErrPluginRegistered
+		}
+	}
+
+	plugins = append(plugins, &pluginRegistration{name: name, plugin: plugin, flags: flags})
+	return nil
+}
+
+func init() {
+	pluginhost.Initialize = hostInitialize
+	pluginhost.List = hostList
+	pluginhost.IO = hostIO
+	pluginhost.Close = hostClose
+}
+
+// lookupPlugin returns the registration for a plugin id (1-based index).
+func lookupPlugin(id uint64) *pluginRegistration {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+
+	if id == 0 || id > uint64(len(plugins)) {
+		return nil
+	}
+	return plugins[id-1]
+}
+
+func pluginPath(path string) string {
+	return strings.Trim(strings.ReplaceAll(path, "\\", "/"), "/")
+}
+
+func hostInitialize(handle unsafe.Pointer) []pluginhost.Registration {
+	v := &Vmm{handle: vmmHandle(C.VMM_HANDLE(handle))}
+
+	pluginsMu.RLock()
+	registrations := append([]*pluginRegistration(nil), plugins...)
+	pluginsMu.RUnlock()
+
+	var result []pluginhost.Registration
+	for i, registration := range registrations {
+		if initializer, ok := registration.plugin.(PluginInitializer); ok {
+			if err := initializer.Initialize(v); err != nil {
+				continue
+			}
+		}
+
+		path, err := vfsPath(registration.name)
+		if err != nil {
+			continue
+		}
+
+		result = append(result, pluginhost.Registration{
+			ID:      uint64(i + 1),
+			Path:    path,
+			Root:    registration.flags&PluginRootModule != 0,
+			Process: registration.flags&PluginProcessModule != 0,
+		})
+	}
+	return result
+}
+
+func hostList(id uint64, pid uint32, path string) ([]pluginhost.Entry, bool) {
+	registration := lookupPlugin(id)
+	if registration == nil {
+		return nil, false
+	}
+
+	entries, err := registration.plugin.List(context.Background(), pid, pluginPath(path))
+	if err != nil {
+		return nil, false
+	}
+
+	result := make([]pluginhost.Entry, len(entries))
+	for i, entry := range entries {
+		result[i] = pluginhost.Entry{
+			Name:           entry.Name,
+			Size:           entry.Size,
+			IsDir:          entry.IsDir,
+			Compressed:     entry.Compressed,
+			CreationTime:   timeToFileTime(entry.CreationTime),
+			LastAccessTime: timeToFileTime(entry.LastAccessTime),
+			LastWriteTime:  timeToFileTime(entry.LastWriteTime),
+		}
+	}
+	return result, true
+}
+
+func hostIO(id uint64, write bool, pid uint32, path string, p []byte, offset uint64) (int, uint32) {
+	registration := lookupPlugin(id)
+	if registration == nil {
+		return 0, VfsStatusFileInvalid
+	}
+
+	fn := registration.plugin.Read
+	if write {
+		fn = registration.plugin.Write
+	}
+
+	n, err := fn(context.Background(), pid, pluginPath(path), p, offset)
+	if n < 0 || n > len(p) {
+		return 0, VfsStatusUnsuccessful
+	}
+
+	switch {
+	case err == nil:
+		return n, VfsStatusSuccess
+	case errors.Is(err, io.EOF):
+		if n > 0 {
+			return n, VfsStatusSuccess
+		}
+		return n, VfsStatusEndOfFile
+	default:
+		return n, VfsStatusUnsuccessful
+	}
+}
+
+func hostClose(id uint64) {
+	registration := lookupPlugin(id)
+	if registration == nil {
+		return
+	}
+
+	if closer, ok := registration.plugin.(PluginCloser); ok {
+		closer.Close()
+	}
+}
+
+func (v *Vmm) initializePlugins() error {
+	if C.VMMDLL_InitializePlugins(v.handle) == 0 {
+		return fmt.Errorf("failed to initialize plugins")
+	}
+	return nil
+}
+
+// InitializePlugins loads the native plugins (m_*.so / m_*.dll) found in the
+// plugins directory, including plugins built with RegisterPlugin.
+func (v *Vmm) InitializePlugins(ctx context.Context) error {
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- v.initializePlugins()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errChan:
+		return err
+	}
+}
@@ -0,0 +1,208 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unsafe"
+)
+
+// Pool map flags
+const (
+	PoolMapFlagAll = 0x00000000 // retrieve all pool allocations
+	PoolMapFlagBig = 0x00000001 // retrieve big pool allocations only
+)
+
+type PoolType uint8
+
+const (
+	PoolTypeUnknown PoolType = iota
+	PoolTypeNonPaged
+	PoolTypeNonPagedNx
+	PoolTypePaged
+)
+
+func (p PoolType) String() string {
+	switch p {
+	case PoolTypeUnknown:
+		return "Unknown"
+	case PoolTypeNonPaged:
+		return "NonPaged"
+	case PoolTypeNonPagedNx:
+		return "NonPagedNx"
+	case PoolTypePaged:
+		return "Paged"
+	default:
+		return "Unknown"
+	}
+}
+
+type PoolSubsegmentType uint8
+
+const (
+	PoolSubsegmentTypeUnknown PoolSubsegmentType = iota
+	PoolSubsegmentTypeNA
+	PoolSubsegmentTypeBig
+	PoolSubsegmentTypeLarge
+	PoolSubsegmentTypeVS
+	PoolSubsegmentTypeLFH
+)
+
+func (p PoolSubsegmentType) String() string {
+	switch p {
+	case PoolSubsegmentTypeUnknown:
+		return "Unknown"
+	case PoolSubsegmentTypeNA:
+		return "N/A"
+	case PoolSubsegmentTypeBig:
+		return "Big"
+	case PoolSubsegmentTypeLarge:
+		return "Large"
+	case PoolSubsegmentTypeVS:
+		return "VS"
+	case PoolSubsegmentTypeLFH:
+		return "LFH"
+	default:
+		return "Unknown"
+	}
+}
+
+type PoolEntry struct {
+	Va             uint64
+	Tag            string
+	Size           uint32
+	Alloc          bool
+	Type           PoolType
+	SubsegmentType PoolSubsegmentType
+}
+
+// Paged reports whether the allocation resides in paged pool.
+func (e PoolEntry) Paged() bool {
+	return e.Type == PoolTypePaged
+}
+
+type Pool struct {
+	Version   uint32
+	TotalSize uint32
+	Entries   []PoolEntry
+
+	byTag map[string][]int
+	byVa  []int
+}
+
+// ByTag returns all pool entries with the given 4-character tag, e.g. "Proc" or "Thre".
+func (p *Pool) ByTag(tag string) []PoolEntry {
+	indexes := p.byTag[tag]
+	result := make([]PoolEntry, len(indexes))
+	for i, index := range indexes {
+		result[i] = p.Entries[index]
+	}
+	return result
+}
+
+// Contains returns the pool entry whose allocation contains va.
+func (p *Pool) Contains(va uint64) (*PoolEntry, bool) {
+	n := sort.Search(len(p.byVa), func(i int) bool {
+		return p.Entries[p.byVa[i]].Va > va
+	})
+	if n == 0 {
+		return nil, false
+	}
+	entry := &p.Entries[p.byVa[n-1]]
+	if va >= entry.Va+uint64(entry.Size) {
+		return nil, false
+	}
+	return entry, true
+}
+
+func (p *Pool) buildIndex() {
+	p.byTag = make(map[string][]int)
+	p.byVa = make([]int, len(p.Entries))
+
+	for i, entry := range p.Entries {
+		p.byTag[entry.Tag] = append(p.byTag[entry.Tag], i)
+		p.byVa[i] = i
+	}
+
+	sort.Slice(p.byVa, func(a, b int) bool {
+		return p.Entries[p.byVa[a]].Va < p.Entries[p.byVa[b]].Va
+	})
+}
+
+func newPoolEntry(cEntry *C.VMMDLL_MAP_POOLENTRY) PoolEntry {
+	// union { CHAR szTag[5]; struct { DWORD dwTag; BYTE _ReservedZero; BYTE fAlloc; BYTE tpPool; BYTE tpSS; }; };
+	tag := (*[8]byte)(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.va), unsafe.Sizeof(cEntry.va)))
+
+	return PoolEntry{
+		Va:             uint64(cEntry.va),
+		Tag:            string(tag[:4]),
+		Size:           uint32(cEntry.cb),
+		Alloc:          tag[5] != 0,
+		Type:           PoolType(tag[6]),
+		SubsegmentType: PoolSubsegmentType(tag[7]),
+	}
+}
+
+func newPool(cPool *C.VMMDLL_MAP_POOL) Pool {
+	count := int(cPool.cMap)
+
+	pool := Pool{
+		Version:   uint32(cPool.dwVersion),
+		TotalSize: uint32(cPool.cbTotal),
+		Entries:   make([]PoolEntry, count),
+	}
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_POOLENTRY](afterDWORD(unsafe.Pointer(&cPool.cMap)), count) {
+		pool.Entries[i] = newPoolEntry(cEntry)
+	}
+
+	pool.buildIndex()
+
+	return pool
+}
+
+func (vmm *Vmm) getPoolMap(flags uint32) (*Pool, error) {
+	var cPoolMap C.PVMMDLL_MAP_POOL
+	success := C.VMMDLL_Map_GetPool(C.VMM_HANDLE(vmm.handle), &cPoolMap, C.DWORD(flags))
+
+	if success == 0 || cPoolMap == nil {
+		return nil, fmt.Errorf("failed to get pool map")
+	}
+
+	defer freeMemory(C.PVOID(cPoolMap))
+
+	if cPoolMap.dwVersion != MapPoolVersion {
+		return nil, ErrUnsupportedPoolVersion
+	}
+
+	pool := newPool(cPoolMap)
+
+	return &pool, nil
+}
+
+// GetPoolMap retrieves the kernel pool allocations, flags is PoolMapFlagAll or PoolMapFlagBig.
+func (vmm *Vmm) GetPoolMap(ctx context.Context, flags uint32) (*Pool, error) {
+	resultChan := make(chan struct {
+		pool *Pool
+		err  error
+	}, 1)
+
+	go func() {
+		pool, err := vmm.getPoolMap(flags)
+		resultChan <- struct {
+			pool *Pool
+			err  error
+		}{pool, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.pool, result.err
+	}
+}
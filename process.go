@@ -7,7 +7,11 @@ import "C"
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 	"unsafe"
 )
 
@@ -911,9 +915,162 @@ func newModule(cMod *C.VMMDLL_MAP_MODULE) Module {
 	return mod
 }
 
-/**
-todo :VMMDLL_Map_GetVadEx
-*/
+type PageType uint32
+
+const (
+	PageTypeNA PageType = iota
+	PageTypeHardware
+	PageTypeTransition
+	PageTypePrototype
+	PageTypeDemandZero
+	PageTypeCompressed
+	PageTypePagefile
+	PageTypeFile
+)
+
+func (p PageType) String() string {
+	switch p {
+	case PageTypeNA:
+		return "N/A"
+	case PageTypeHardware:
+		return "Hardware"
+	case PageTypeTransition:
+		return "Transition"
+	case PageTypePrototype:
+		return "Prototype"
+	case PageTypeDemandZero:
+		return "DemandZero"
+	case PageTypeCompressed:
+		return "Compressed"
+	case PageTypePagefile:
+		return "Pagefile"
+	case PageTypeFile:
+		return "File"
+	default:
+		return "Unknown"
+	}
+}
+
+type VADExPrototype struct {
+	Type PageType
+	Pa   uint64
+	Pte  uint64
+}
+
+type VADExEntry struct {
+	Type      PageType
+	PML       uint8
+	PteFlags  uint8
+	Va        uint64
+	Pa        uint64
+	Pte       uint64
+	Proto     VADExPrototype
+	VaVadBase uint64
+
+	// Vad is the VAD entry the page belongs to, nil if it could not be resolved.
+	Vad *VADEntry
+}
+
+// EffectiveType returns the page type after following the prototype PTE (if any).
+func (e VADExEntry) EffectiveType() PageType {
+	if e.Type == PageTypePrototype {
+		return e.Proto.Type
+	}
+	return e.Type
+}
+
+type VADEx struct {
+	Version uint32
+	Entries []VADExEntry
+}
+
+func newVADExEntry(cEntry *C.VMMDLL_MAP_VADEXENTRY) VADExEntry {
+	return VADExEntry{
+		Type:     PageType(cEntry.tp),
+		PML:      uint8(cEntry.iPML),
+		PteFlags: uint8(cEntry.pteFlags),
+		Va:       uint64(cEntry.va),
+		Pa:       uint64(cEntry.pa),
+		Pte:      uint64(cEntry.pte),
+		Proto: VADExPrototype{
+			Type: PageType(cEntry.proto.tp),
+			Pa:   uint64(cEntry.proto.pa),
+			Pte:  uint64(cEntry.proto.pte),
+		},
+		VaVadBase: uint64(cEntry.vaVadBase),
+	}
+}
+
+func newVADEx(cVadEx *C.VMMDLL_MAP_VADEX, vad *VAD) VADEx {
+	count := int(cVadEx.cMap)
+
+	vads := make(map[uint64]*VADEntry)
+	if vad != nil {
+		for i := range vad.MapEntries {
+			vads[vad.MapEntries[i].VaStart] = &vad.MapEntries[i]
+		}
+	}
+
+	entries := make([]VADExEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_VADEXENTRY](afterDWORD(unsafe.Pointer(&cVadEx.cMap)), count) {
+		entry := newVADExEntry(cEntry)
+		entry.Vad = vads[entry.VaVadBase]
+		entries[i] = entry
+	}
+
+	return VADEx{
+		Version: uint32(cVadEx.dwVersion),
+		Entries: entries,
+	}
+}
+
+func (vmm *Vmm) getProcessMapVADEx(pid uint32, oPage uint32, cPage uint32) (*VADEx, error) {
+	vad, err := vmm.getProcessMapVAD(pid, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var cVadExMap C.PVMMDLL_MAP_VADEX
+	success := C.VMMDLL_Map_GetVadEx(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), C.DWORD(oPage), C.DWORD(cPage), &cVadExMap)
+
+	if success == 0 || cVadExMap == nil {
+		return nil, fmt.Errorf("failed to get VADEx map for process %d", pid)
+	}
+
+	defer freeMemory(C.PVOID(cVadExMap))
+
+	if cVadExMap.dwVersion != MapVADExVersion {
+		return nil, ErrUnsupportedVADExVersion
+	}
+
+	vadEx := newVADEx(cVadExMap, vad)
+
+	return &vadEx, nil
+}
+
+// GetProcessMapVADEx retrieves per-page information for cPage pages starting at page
+// index oPage, counted over all pages in the process VAD map (see VAD.PageCount).
+func (vmm *Vmm) GetProcessMapVADEx(ctx context.Context, pid uint32, oPage uint32, cPage uint32) (*VADEx, error) {
+	resultChan := make(chan struct {
+		vadEx *VADEx
+		err   error
+	}, 1)
+
+	go func() {
+		vadEx, err := vmm.getProcessMapVADEx(pid, oPage, cPage)
+		resultChan <- struct {
+			vadEx *VADEx
+			err   error
+		}{vadEx, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.vadEx, result.err
+	}
+}
 
 func (vmm *Vmm) getProcessModuleList(pid uint32, flags uint32) (*Module, error) {
 	var cModules C.PVMMDLL_MAP_MODULE
@@ -950,3 +1107,622 @@ func (vmm *Vmm) GetProcessModuleList(ctx context.Context, pid uint32, flags uint
 		return result.module, result.err
 	}
 }
+
+type UnloadedModuleEntry struct {
+	VaBase        uint64
+	ImageSize     uint32
+	WoW64         bool
+	Name          string
+	CheckSum      uint32
+	TimeDateStamp uint32
+	UnloadTime    time.Time
+}
+
+type UnloadedModule struct {
+	Version   uint32
+	MultiText []string
+	Entries   []UnloadedModuleEntry
+}
+
+func newUnloadedModuleEntry(cEntry *C.VMMDLL_MAP_UNLOADEDMODULEENTRY) UnloadedModuleEntry {
+	return UnloadedModuleEntry{
+		VaBase:        uint64(cEntry.vaBase),
+		ImageSize:     uint32(cEntry.cbImageSize),
+		WoW64:         cEntry.fWoW64 != 0,
+		Name:          readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.fWoW64), unsafe.Sizeof(cEntry.fWoW64))),
+		CheckSum:      uint32(cEntry.dwCheckSum),
+		TimeDateStamp: uint32(cEntry.dwTimeDateStamp),
+		UnloadTime:    fileTimeToTime(uint64(cEntry.ftUnload)),
+	}
+}
+
+func newUnloadedModule(cMod *C.VMMDLL_MAP_UNLOADEDMODULE) UnloadedModule {
+	mod := UnloadedModule{
+		Version: uint32(cMod.dwVersion),
+	}
+
+	if cMod.pbMultiText != nil && cMod.cbMultiText > 0 {
+		mod.MultiText = multiString(C.GoBytes(unsafe.Pointer(cMod.pbMultiText), C.int(cMod.cbMultiText)))
+	}
+
+	count := int(cMod.cMap)
+	mod.Entries = make([]UnloadedModuleEntry, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_UNLOADEDMODULEENTRY](afterDWORD(unsafe.Pointer(&cMod.cMap)), count) {
+		mod.Entries[i] = newUnloadedModuleEntry(cEntry)
+	}
+
+	return mod
+}
+
+func (vmm *Vmm) getProcessMapUnloadedModule(pid uint32) (*UnloadedModule, error) {
+	var cUnloadedModuleMap C.PVMMDLL_MAP_UNLOADEDMODULE
+	success := C.VMMDLL_Map_GetUnloadedModuleU(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), &cUnloadedModuleMap)
+
+	if success == 0 || cUnloadedModuleMap == nil {
+		return nil, fmt.Errorf("failed to get unloaded module map for process %d", pid)
+	}
+
+	defer freeMemory(C.PVOID(cUnloadedModuleMap))
+
+	if cUnloadedModuleMap.dwVersion != MapUnloadedModuleVersion {
+		return nil, ErrUnsupportedUnloadedModuleVersion
+	}
+
+	module := newUnloadedModule(cUnloadedModuleMap)
+
+	return &module, nil
+}
+
+func (vmm *Vmm) GetProcessMapUnloadedModule(ctx context.Context, pid uint32) (*UnloadedModule, error) {
+	resultChan := make(chan struct {
+		module *UnloadedModule
+		err    error
+	}, 1)
+
+	go func() {
+		module, err := vmm.getProcessMapUnloadedModule(pid)
+		resultChan <- struct {
+			module *UnloadedModule
+			err    error
+		}{module, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.module, result.err
+	}
+}
+
+type EATEntry struct {
+	VaFunction           uint64
+	Ordinal              uint32
+	Rva                  uint32
+	FunctionsArrayOffset uint32
+	NamesArrayOffset     uint32
+	Name                 string
+	ForwardedFunction    string
+}
+
+type EAT struct {
+	Version                    uint32
+	OrdinalBase                uint32
+	NumberOfNames              uint32
+	NumberOfFunctions          uint32
+	NumberOfForwardedFunctions uint32
+	VaModuleBase               uint64
+	VaAddressOfFunctions       uint64
+	VaAddressOfNames           uint64
+	MultiText                  []string
+	Entries                    []EATEntry
+
+	byName    map[string]int
+	byOrdinal map[uint32]int
+	byVa      []int
+}
+
+// Lookup returns the export with the given (case-sensitive) name.
+func (e *EAT) Lookup(name string) (*EATEntry, bool) {
+	i, ok := e.byName[name]
+	if !ok {
+		return nil, false
+	}
+	return &e.Entries[i], true
+}
+
+// ByOrdinal returns the export with the given ordinal (OrdinalBase included).
+func (e *EAT) ByOrdinal(ordinal uint32) (*EATEntry, bool) {
+	i, ok := e.byOrdinal[ordinal]
+	if !ok {
+		return nil, false
+	}
+	return &e.Entries[i], true
+}
+
+// Nearest returns the closest non-forwarded export at or below va together with the
+// displacement of va from it. It fails if va lies below the first export.
+func (e *EAT) Nearest(va uint64) (*EATEntry, uint64, bool) {
+	n := sort.Search(len(e.byVa), func(i int) bool {
+		return e.Entries[e.byVa[i]].VaFunction > va
+	})
+	if n == 0 {
+		return nil, 0, false
+	}
+	entry := &e.Entries[e.byVa[n-1]]
+	return entry, va - entry.VaFunction, true
+}
+
+func (e *EAT) buildIndex() {
+	e.byName = make(map[string]int, len(e.Entries))
+	e.byOrdinal = make(map[uint32]int, len(e.Entries))
+	e.byVa = make([]int, 0, len(e.Entries))
+
+	for i, entry := range e.Entries {
+		if entry.Name != "" {
+			e.byName[entry.Name] = i
+		}
+		e.byOrdinal[entry.Ordinal] = i
+		if entry.VaFunction != 0 && entry.ForwardedFunction == "" {
+			e.byVa = append(e.byVa, i)
+		}
+	}
+
+	sort.Slice(e.byVa, func(a, b int) bool {
+		return e.Entries[e.byVa[a]].VaFunction < e.Entries[e.byVa[b]].VaFunction
+	})
+}
+
+func newEATEntry(cEntry *C.VMMDLL_MAP_EATENTRY, vaModuleBase uint64) EATEntry {
+	uszFunctionPtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry._FutureUse1), unsafe.Sizeof(cEntry._FutureUse1))
+	uszForwardedFunctionPtr := unsafe.Pointer(uintptr(uszFunctionPtr) + unsafe.Sizeof(uintptr(0)))
+
+	entry := EATEntry{
+		VaFunction:           uint64(cEntry.vaFunction),
+		Ordinal:              uint32(cEntry.dwOrdinal),
+		FunctionsArrayOffset: uint32(cEntry.oFunctionsArray),
+		NamesArrayOffset:     uint32(cEntry.oNamesArray),
+		Name:                 C.GoString(*(**C.char)(uszFunctionPtr)),
+		ForwardedFunction:    C.GoString(*(**C.char)(uszForwardedFunctionPtr)),
+	}
+
+	if entry.VaFunction >= vaModuleBase {
+		entry.Rva = uint32(entry.VaFunction - vaModuleBase)
+	}
+
+	return entry
+}
+
+func newEAT(cEat *C.VMMDLL_MAP_EAT) EAT {
+	eat := EAT{
+		Version:                    uint32(cEat.dwVersion),
+		OrdinalBase:                uint32(cEat.dwOrdinalBase),
+		NumberOfNames:              uint32(cEat.cNumberOfNames),
+		NumberOfFunctions:          uint32(cEat.cNumberOfFunctions),
+		NumberOfForwardedFunctions: uint32(cEat.cNumberOfForwardedFunctions),
+		VaModuleBase:               uint64(cEat.vaModuleBase),
+		VaAddressOfFunctions:       uint64(cEat.vaAddressOfFunctions),
+		VaAddressOfNames:           uint64(cEat.vaAddressOfNames),
+	}
+
+	if cEat.pbMultiText != nil && cEat.cbMultiText > 0 {
+		eat.MultiText = multiString(C.GoBytes(unsafe.Pointer(cEat.pbMultiText), C.int(cEat.cbMultiText)))
+	}
+
+	count := int(cEat.cMap)
+	eat.Entries = make([]EATEntry, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_EATENTRY](afterDWORD(unsafe.Pointer(&cEat.cMap)), count) {
+		eat.Entries[i] = newEATEntry(cEntry, eat.VaModuleBase)
+	}
+
+	eat.buildIndex()
+
+	return eat
+}
+
+func (vmm *Vmm) getProcessMapEAT(pid uint32, module string) (*EAT, error) {
+	cModule := C.CString(module)
+	defer C.free(unsafe.Pointer(cModule))
+
+	var cEatMap C.PVMMDLL_MAP_EAT
+	success := C.VMMDLL_Map_GetEATU(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), cModule, &cEatMap)
+
+	if success == 0 || cEatMap == nil {
+		return nil, fmt.Errorf("failed to get EAT map for process %d, module %s", pid, module)
+	}
+
+	defer freeMemory(C.PVOID(cEatMap))
+
+	if cEatMap.dwVersion != MapEATVersion {
+		return nil, ErrUnsupportedEATVersion
+	}
+
+	eat := newEAT(cEatMap)
+
+	return &eat, nil
+}
+
+func (vmm *Vmm) GetProcessMapEAT(ctx context.Context, pid uint32, module string) (*EAT, error) {
+	resultChan := make(chan struct {
+		eat *EAT
+		err error
+	}, 1)
+
+	go func() {
+		eat, err := vmm.getProcessMapEAT(pid, module)
+		resultChan <- struct {
+			eat *EAT
+			err error
+		}{eat, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.eat, result.err
+	}
+}
+
+type IATEntry struct {
+	VaThunk               uint64
+	VaFunction            uint64
+	Module                string
+	ImportedModule        string
+	Function              string
+	Is32                  bool
+	Hint                  uint16
+	RvaFirstThunk         uint32
+	RvaOriginalFirstThunk uint32
+	RvaNameModule         uint32
+	RvaNameFunction       uint32
+}
+
+type IAT struct {
+	Version      uint32
+	VaModuleBase uint64
+	Module       string
+	MultiText    []string
+	Entries      []IATEntry
+}
+
+// ExportResolver returns the export address table of a module loaded in the inspected
+// process, typically a closure over Vmm.GetProcessMapEAT.
+type ExportResolver func(module string) (*EAT, error)
+
+type IATHook struct {
+	Entry *IATEntry
+	// ExpectedModule is the module the import finally resolves to, after following
+	// forwarded exports and api-set contracts.
+	ExpectedModule string
+	// Expected is the resolved export address, 0 if unknown (e.g. ordinal imports).
+	Expected uint64
+	// TargetModule is the module containing the current target, "" if none does.
+	TargetModule string
+}
+
+// maxForwardDepth bounds the number of forwarded exports followed for one import.
+const maxForwardDepth = 8
+
+// isApiSetModule reports whether name is an api-set contract such as
+// api-ms-win-core-synch-l1-2-0.dll, which has no image of its own.
+func isApiSetModule(name string) bool {
+	name = strings.ToLower(name)
+	return strings.HasPrefix(name, "api-") || strings.HasPrefix(name, "ext-")
+}
+
+// moduleKey normalizes a module name as used in forwarders and imports, e.g.
+// "NTDLL" and "ntdll.dll" both become "ntdll.dll".
+func moduleKey(name string) string {
+	name = strings.ToLower(name)
+	if !strings.Contains(name, ".") {
+		name += ".dll"
+	}
+	return name
+}
+
+// splitForwarder splits a forwarded export such as "NTDLL.RtlAllocateHeap" into
+// module and function name.
+func splitForwarder(forward string) (string, string, bool) {
+	n := strings.LastIndexByte(forward, '.')
+	if n <= 0 || n == len(forward)-1 {
+		return "", "", false
+	}
+	return moduleKey(forward[:n]), forward[n+1:], true
+}
+
+// moduleIdentity tells apart the 32-bit and 64-bit copies of a module, e.g. ntdll.dll,
+// both loaded in a WoW64 process.
+type moduleIdentity struct {
+	name  string
+	wow64 bool
+}
+
+type hookResolver struct {
+	resolve ExportResolver
+	modules map[moduleIdentity]*ModuleEntry
+	sorted  []*ModuleEntry
+	eats    map[string]*EAT
+	failed  map[string]bool
+	errs    []error
+	seen    map[string]bool
+
+	// wow64 is the bitness of the importing module, imports resolve to modules of
+	// the same bitness.
+	wow64 bool
+}
+
+// fail records err once, imports of the same module tend to fail the same way.
+func (r *hookResolver) fail(err error) {
+	if r.seen[err.Error()] {
+		return
+	}
+	r.seen[err.Error()] = true
+	r.errs = append(r.errs, err)
+}
+
+func (r *hookResolver) eat(module string) *EAT {
+	key := moduleKey(module)
+	if eat, ok := r.eats[key]; ok {
+		return eat
+	}
+	if r.failed[key] {
+		return nil
+	}
+
+	eat, err := r.resolve(module)
+	if err != nil || eat == nil {
+		r.failed[key] = true
+		if err == nil {
+			err = errors.New("no export table")
+		}
+		r.fail(fmt.Errorf("failed to resolve exports of %s: %w", module, err))
+		return nil
+	}
+
+	r.eats[key] = eat
+	return eat
+}
+
+// containing returns the module whose image contains va.
+func (r *hookResolver) containing(va uint64) *ModuleEntry {
+	n := sort.Search(len(r.sorted), func(i int) bool {
+		return r.sorted[i].VaBase > va
+	})
+	if n == 0 {
+		return nil
+	}
+	module := r.sorted[n-1]
+	if va >= module.VaBase+uint64(module.ImageSize) {
+		return nil
+	}
+	return module
+}
+
+// final follows forwarded exports of function starting at module. It returns the
+// module and address the import finally resolves to. An api-set module is returned
+// as is with address 0 since the host module isn't known.
+func (r *hookResolver) final(module string, function string) (string, uint64, bool) {
+	for depth := 0; depth < maxForwardDepth; depth++ {
+		if isApiSetModule(module) || function == "" {
+			return module, 0, true
+		}
+
+		eat := r.eat(module)
+		if eat == nil {
+			return "", 0, false
+		}
+
+		export, ok := eat.Lookup(function)
+		if !ok {
+			r.fail(fmt.Errorf("%s doesn't export %s", module, function))
+			return "", 0, false
+		}
+		if export.ForwardedFunction == "" {
+			return module, export.VaFunction, true
+		}
+
+		if module, function, ok = splitForwarder(export.ForwardedFunction); !ok {
+			r.fail(fmt.Errorf("invalid forwarder %q", export.ForwardedFunction))
+			return "", 0, false
+		}
+		if strings.HasPrefix(function, "#") {
+			// forwarded by ordinal, only the module is known
+			return module, 0, true
+		}
+	}
+
+	r.fail(fmt.Errorf("forwarder chain of %s!%s too long", module, function))
+	return "", 0, false
+}
+
+// exports reports whether module exports function without forwarding it elsewhere.
+func (r *hookResolver) exports(module *ModuleEntry, function string) bool {
+	eat := r.eat(module.Name)
+	if eat == nil {
+		return false
+	}
+	export, ok := eat.Lookup(function)
+	return ok && export.ForwardedFunction == ""
+}
+
+// DetectHooks reports imports whose current target lies outside the module exporting
+// the function. Forwarded exports are followed to the final module. Api-set imports
+// are accepted if the module containing the target exports the function itself.
+//
+// modules is the module list of the inspected process, see GetProcessModuleList. In a
+// WoW64 process imports are matched against the modules of the importer's bitness.
+// Imports which couldn't be checked are reported in the returned error, the hooks
+// found among the remaining imports are returned regardless.
+func (i *IAT) DetectHooks(modules []ModuleEntry, resolve ExportResolver) ([]IATHook, error) {
+	r := &hookResolver{
+		resolve: resolve,
+		modules: make(map[moduleIdentity]*ModuleEntry, len(modules)),
+		sorted:  make([]*ModuleEntry, 0, len(modules)),
+		eats:    make(map[string]*EAT),
+		failed:  make(map[string]bool),
+		seen:    make(map[string]bool),
+	}
+	for n := range modules {
+		r.modules[moduleIdentity{moduleKey(modules[n].Name), modules[n].WoW64}] = &modules[n]
+		r.sorted = append(r.sorted, &modules[n])
+	}
+	sort.Slice(r.sorted, func(a, b int) bool {
+		return r.sorted[a].VaBase < r.sorted[b].VaBase
+	})
+	if importer := r.containing(i.VaModuleBase); importer != nil {
+		r.wow64 = importer.WoW64
+	}
+
+	var hooks []IATHook
+
+	for n := range i.Entries {
+		entry := &i.Entries[n]
+		if entry.VaFunction == 0 || entry.ImportedModule == "" {
+			continue
+		}
+
+		finalModule, expected, ok := r.final(entry.ImportedModule, entry.Function)
+		if !ok {
+			continue
+		}
+
+		hook := IATHook{
+			Entry:          entry,
+			ExpectedModule: finalModule,
+			Expected:       expected,
+		}
+		target := r.containing(entry.VaFunction)
+		if target != nil {
+			hook.TargetModule = target.Name
+		}
+
+		if isApiSetModule(finalModule) {
+			if entry.Function == "" {
+				r.fail(fmt.Errorf("can't check ordinal import from api-set %s", finalModule))
+				continue
+			}
+			if target != nil && r.exports(target, entry.Function) {
+				continue
+			}
+			hooks = append(hooks, hook)
+			continue
+		}
+
+		module, loaded := r.modules[moduleIdentity{moduleKey(finalModule), r.wow64}]
+		if !loaded {
+			r.fail(fmt.Errorf("module %s isn't loaded", finalModule))
+			continue
+		}
+		hook.ExpectedModule = module.Name
+
+		if target != module {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	return hooks, errors.Join(r.errs...)
+}
+
+// DetectIATHooks checks the imports of module in process pid with IAT.DetectHooks.
+func (vmm *Vmm) DetectIATHooks(ctx context.Context, pid uint32, module string) ([]IATHook, error) {
+	iat, err := vmm.GetProcessMapIAT(ctx, pid, module)
+	if err != nil {
+		return nil, err
+	}
+
+	modules, err := vmm.GetProcessModuleList(ctx, pid, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return iat.DetectHooks(modules.Entries, func(name string) (*EAT, error) {
+		return vmm.GetProcessMapEAT(ctx, pid, name)
+	})
+}
+
+func newIATEntry(cEntry *C.VMMDLL_MAP_IATENTRY, vaModuleBase uint64, module string) IATEntry {
+	uszFunctionPtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.vaFunction), unsafe.Sizeof(cEntry.vaFunction))
+	uszModulePtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry._FutureUse2), unsafe.Sizeof(cEntry._FutureUse2))
+
+	return IATEntry{
+		VaThunk:               vaModuleBase + uint64(cEntry.Thunk.rvaFirstThunk),
+		VaFunction:            uint64(cEntry.vaFunction),
+		Module:                module,
+		ImportedModule:        C.GoString(*(**C.char)(uszModulePtr)),
+		Function:              C.GoString(*(**C.char)(uszFunctionPtr)),
+		Is32:                  cEntry.Thunk.f32 != 0,
+		Hint:                  uint16(cEntry.Thunk.wHint),
+		RvaFirstThunk:         uint32(cEntry.Thunk.rvaFirstThunk),
+		RvaOriginalFirstThunk: uint32(cEntry.Thunk.rvaOriginalFirstThunk),
+		RvaNameModule:         uint32(cEntry.Thunk.rvaNameModule),
+		RvaNameFunction:       uint32(cEntry.Thunk.rvaNameFunction),
+	}
+}
+
+func newIAT(cIat *C.VMMDLL_MAP_IAT, module string) IAT {
+	iat := IAT{
+		Version:      uint32(cIat.dwVersion),
+		VaModuleBase: uint64(cIat.vaModuleBase),
+		Module:       module,
+	}
+
+	if cIat.pbMultiText != nil && cIat.cbMultiText > 0 {
+		iat.MultiText = multiString(C.GoBytes(unsafe.Pointer(cIat.pbMultiText), C.int(cIat.cbMultiText)))
+	}
+
+	count := int(cIat.cMap)
+	iat.Entries = make([]IATEntry, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_IATENTRY](afterDWORD(unsafe.Pointer(&cIat.cMap)), count) {
+		iat.Entries[i] = newIATEntry(cEntry, iat.VaModuleBase, module)
+	}
+
+	return iat
+}
+
+func (vmm *Vmm) getProcessMapIAT(pid uint32, module string) (*IAT, error) {
+	cModule := C.CString(module)
+	defer C.free(unsafe.Pointer(cModule))
+
+	var cIatMap C.PVMMDLL_MAP_IAT
+	success := C.VMMDLL_Map_GetIATU(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), cModule, &cIatMap)
+
+	if success == 0 || cIatMap == nil {
+		return nil, fmt.Errorf("failed to get IAT map for process %d, module %s", pid, module)
+	}
+
+	defer freeMemory(C.PVOID(cIatMap))
+
+	if cIatMap.dwVersion != MapIATVersion {
+		return nil, ErrUnsupportedIATVersion
+	}
+
+	iat := newIAT(cIatMap, module)
+
+	return &iat, nil
+}
+
+func (vmm *Vmm) GetProcessMapIAT(ctx context.Context, pid uint32, module string) (*IAT, error) {
+	resultChan := make(chan struct {
+		iat *IAT
+		err error
+	}, 1)
+
+	go func() {
+		iat, err := vmm.getProcessMapIAT(pid, module)
+		resultChan <- struct {
+			iat *IAT
+			err error
+		}{iat, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.iat, result.err
+	}
+}
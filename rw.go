@@ -5,6 +5,7 @@ package go_memprocfs
 */
 import "C"
 import (
+	"context"
 	"errors"
 	"unsafe"
 )
@@ -55,9 +56,115 @@ func (v *Vmm) MemVirt2Phys(pid uint32, va uint64) (uint64, error) {
 	return pa, nil
 }
 
-/*
-todo
-VMMDLL_MemReadScatter
-VMMDLL_MemWriteScatter
-VMMDLL_MemReadPage
-*/
+func (v *Vmm) memReadScatter(pid uint32, scatters []*MemScatter, flags VMMFlag) ([]bool, error) {
+	if len(scatters) == 0 {
+		return nil, nil
+	}
+
+	ppMEMs, release, err := acquireMemScatters(scatters)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	C.VMMDLL_MemReadScatter(v.handle, C.DWORD(pid), (C.PPMEM_SCATTER)(unsafe.Pointer(&ppMEMs[0])), C.DWORD(len(ppMEMs)), C.DWORD(flags))
+
+	return memScatterResults(scatters), nil
+}
+
+// MemReadScatter reads all scatters in one call and returns the per-entry success,
+// the data is available from MemScatter.Data. A MemScatter must not be used by
+// concurrent scatter calls.
+func (v *Vmm) MemReadScatter(ctx context.Context, pid uint32, scatters []*MemScatter, flags VMMFlag) ([]bool, error) {
+	resultChan := make(chan struct {
+		success []bool
+		err     error
+	}, 1)
+
+	go func() {
+		success, err := v.memReadScatter(pid, scatters, flags)
+		resultChan <- struct {
+			success []bool
+			err     error
+		}{success, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.success, result.err
+	}
+}
+
+func (v *Vmm) memWriteScatter(pid uint32, scatters []*MemScatter) ([]bool, error) {
+	if len(scatters) == 0 {
+		return nil, nil
+	}
+
+	ppMEMs, release, err := acquireMemScatters(scatters)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	C.VMMDLL_MemWriteScatter(v.handle, C.DWORD(pid), (C.PPMEM_SCATTER)(unsafe.Pointer(&ppMEMs[0])), C.DWORD(len(ppMEMs)))
+
+	return memScatterResults(scatters), nil
+}
+
+// MemWriteScatter writes the data set with MemScatter.SetData and returns the
+// per-entry success.
+func (v *Vmm) MemWriteScatter(ctx context.Context, pid uint32, scatters []*MemScatter) ([]bool, error) {
+	resultChan := make(chan struct {
+		success []bool
+		err     error
+	}, 1)
+
+	go func() {
+		success, err := v.memWriteScatter(pid, scatters)
+		resultChan <- struct {
+			success []bool
+			err     error
+		}{success, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.success, result.err
+	}
+}
+
+func (v *Vmm) memReadPage(pid uint32, va uint64) ([]byte, error) {
+	page := make([]byte, pageSize)
+	success := C.VMMDLL_MemReadPage(v.handle, C.DWORD(pid), C.ULONG64(va), (*C.BYTE)(unsafe.Pointer(&page[0])))
+	if success == 0 {
+		return nil, errors.New("failed to read page")
+	}
+	return page, nil
+}
+
+// MemReadPage reads the 4 KiB page containing va.
+func (v *Vmm) MemReadPage(ctx context.Context, pid uint32, va uint64) ([]byte, error) {
+	resultChan := make(chan struct {
+		page []byte
+		err  error
+	}, 1)
+
+	go func() {
+		page, err := v.memReadPage(pid, va&^(pageSize-1))
+		resultChan <- struct {
+			page []byte
+			err  error
+		}{page, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.page, result.err
+	}
+}
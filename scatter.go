@@ -1,12 +1,14 @@
 package go_memprocfs
 
 /*
+#include <stdlib.h>
 #include "vmmdll.h"
 */
 import "C"
 import (
 	"context"
 	"errors"
+	"sync"
 	"unsafe"
 )
 
@@ -16,10 +18,94 @@ type ScatterTask struct {
 	handle scatterHandle
 	pid    uint32
 	flags  uint32
+
+	// mu is held while MemProcFS may access the C buffers of reads and writes
+	mu      sync.Mutex
+	reads   []*ScatterResult
+	buffers []unsafe.Pointer
+}
+
+// ScatterResult is a read prepared with PrepareRead. The data is read into a C
+// buffer and copied to Go memory once the read has been executed.
+type ScatterResult struct {
+	address uint64
+	size    uint32
+
+	// C memory: DWORD bytes read followed by the data
+	cBuffer unsafe.Pointer
+
+	mu       sync.RWMutex
+	executed bool
+	data     []byte
+	read     uint32
+}
+
+func (r *ScatterResult) Address() uint64 {
+	return r.address
+}
+
+func (r *ScatterResult) Size() uint32 {
+	return r.size
+}
+
+// Executed reports whether the read has been executed by Execute or ExecuteRead.
+func (r *ScatterResult) Executed() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.executed
+}
+
+// Bytes returns the Size bytes read, parts that couldn't be read are zero. It's nil
+// until the read has been executed.
+func (r *ScatterResult) Bytes() []byte {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.data
+}
+
+// BytesRead returns the number of bytes successfully read.
+func (r *ScatterResult) BytesRead() uint32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.read
+}
+
+func (r *ScatterResult) pcbRead() *C.DWORD {
+	return (*C.DWORD)(r.cBuffer)
+}
+
+func (r *ScatterResult) pb() unsafe.Pointer {
+	return unsafe.Add(r.cBuffer, unsafe.Sizeof(C.DWORD(0)))
+}
+
+// collect copies the executed reads to Go memory, s.mu must be held.
+func (s *ScatterTask) collect() {
+	for _, r := range s.reads {
+		r.mu.Lock()
+		r.data = C.GoBytes(r.pb(), C.int(r.size))
+		r.read = uint32(*r.pcbRead())
+		r.executed = true
+		r.mu.Unlock()
+	}
+}
+
+// release frees the C buffers of the prepared reads and writes, s.mu must be held.
+// Results keep the data copied by collect.
+func (s *ScatterTask) release() {
+	for _, r := range s.reads {
+		C.free(r.cBuffer)
+		r.cBuffer = nil
+	}
+	for _, buffer := range s.buffers {
+		C.free(buffer)
+	}
+	s.reads = nil
+	s.buffers = nil
 }
 
 var ErrScatterInitFailed = errors.New("failed to initialize scatter handle")
 var ErrScatterCommandFailed = errors.New("failed to execute scatter command")
+var ErrScatterAlloc = errors.New("failed to allocate scatter read buffer")
 
 func InitializeScatter(vmm *Vmm, pid uint32, flags uint32) (*ScatterTask, error) {
 	h := C.VMMDLL_Scatter_Initialize(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), C.DWORD(flags))
@@ -33,7 +119,11 @@ func (s *ScatterTask) Close(ctx context.Context) error {
 	done := make(chan bool, 1)
 
 	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
 		C.VMMDLL_Scatter_CloseHandle(C.VMMDLL_SCATTER_HANDLE(s.handle))
+		s.release()
 		done <- true
 	}()
 
@@ -49,7 +139,11 @@ func (s *ScatterTask) Clear(ctx context.Context) error {
 	resultChan := make(chan error, 1)
 
 	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
 		success := C.VMMDLL_Scatter_Clear(C.VMMDLL_SCATTER_HANDLE(s.handle), C.DWORD(s.pid), C.DWORD(s.flags))
+		s.release()
 		if success != 0 {
 			resultChan <- nil
 		} else {
@@ -65,24 +159,53 @@ func (s *ScatterTask) Clear(ctx context.Context) error {
 	}
 }
 
-func (s *ScatterTask) PrepareRead(ctx context.Context, address uint64, size uint32, buffer unsafe.Pointer) error {
-	errChan := make(chan error, 1)
+// PrepareRead prepares a read of size bytes at address. The returned result holds
+// the data once ExecuteRead or Execute has completed and stays valid after Clear.
+func (s *ScatterTask) PrepareRead(ctx context.Context, address uint64, size uint32) (*ScatterResult, error) {
+	resultChan := make(chan struct {
+		result *ScatterResult
+		err    error
+	}, 1)
 
 	go func() {
-		success := C.VMMDLL_Scatter_PrepareEx(C.VMMDLL_SCATTER_HANDLE(s.handle), C.QWORD(address), C.DWORD(size), C.PBYTE(buffer), nil)
+		s.mu.Lock()
+		defer s.mu.Unlock()
 
+		r := &ScatterResult{
+			address: address,
+			size:    size,
+			cBuffer: C.calloc(1, C.size_t(unsafe.Sizeof(C.DWORD(0)))+C.size_t(size)),
+		}
+		if r.cBuffer == nil {
+			resultChan <- struct {
+				result *ScatterResult
+				err    error
+			}{nil, ErrScatterAlloc}
+			return
+		}
+
+		success := C.VMMDLL_Scatter_PrepareEx(C.VMMDLL_SCATTER_HANDLE(s.handle), C.QWORD(address), C.DWORD(size), C.PBYTE(r.pb()), r.pcbRead())
 		if success == 0 {
-			errChan <- ErrScatterCommandFailed
-		} else {
-			errChan <- nil
+			C.free(r.cBuffer)
+			resultChan <- struct {
+				result *ScatterResult
+				err    error
+			}{nil, ErrScatterCommandFailed}
+			return
 		}
+
+		s.reads = append(s.reads, r)
+		resultChan <- struct {
+			result *ScatterResult
+			err    error
+		}{r, nil}
 	}()
 
 	select {
 	case <-ctx.Done():
-		return ctx.Err()
-	case err := <-errChan:
-		return err
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.result, result.err
 	}
 }
 
@@ -90,7 +213,11 @@ func (s *ScatterTask) ExecuteRead(ctx context.Context) error {
 	errChan := make(chan error, 1)
 
 	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
 		success := C.VMMDLL_Scatter_ExecuteRead(C.VMMDLL_SCATTER_HANDLE(s.handle))
+		s.collect()
 
 		if success == 0 {
 			errChan <- ErrScatterCommandFailed
@@ -107,15 +234,27 @@ func (s *ScatterTask) ExecuteRead(ctx context.Context) error {
 	}
 }
 
-func (s *ScatterTask) PrepareWrite(ctx context.Context, address uint64, size uint32, buffer unsafe.Pointer) error {
+// PrepareWrite prepares a write of data to address. data is copied and may be
+// reused once PrepareWrite has returned.
+func (s *ScatterTask) PrepareWrite(ctx context.Context, address uint64, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+
 	errChan := make(chan error, 1)
+	buffer := C.CBytes(data)
 
 	go func() {
-		success := C.VMMDLL_Scatter_PrepareWriteEx(C.VMMDLL_SCATTER_HANDLE(s.handle), C.QWORD(address), C.PBYTE(buffer), C.DWORD(size))
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		success := C.VMMDLL_Scatter_PrepareWriteEx(C.VMMDLL_SCATTER_HANDLE(s.handle), C.QWORD(address), C.PBYTE(buffer), C.DWORD(len(data)))
 
 		if success == 0 {
+			C.free(buffer)
 			errChan <- ErrScatterCommandFailed
 		} else {
+			s.buffers = append(s.buffers, buffer)
 			errChan <- nil
 		}
 	}()
@@ -132,7 +271,11 @@ func (s *ScatterTask) Execute(ctx context.Context) error {
 	errChan := make(chan error, 1)
 
 	go func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
 		success := C.VMMDLL_Scatter_Execute(C.VMMDLL_SCATTER_HANDLE(s.handle))
+		s.collect()
 
 		if success == 0 {
 			errChan <- ErrScatterInitFailed
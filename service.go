@@ -0,0 +1,207 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"unsafe"
+)
+
+type ServiceStartType uint32
+
+const (
+	ServiceStartBoot ServiceStartType = iota
+	ServiceStartSystem
+	ServiceStartAuto
+	ServiceStartDemand
+	ServiceStartDisabled
+)
+
+func (s ServiceStartType) String() string {
+	switch s {
+	case ServiceStartBoot:
+		return "Boot"
+	case ServiceStartSystem:
+		return "System"
+	case ServiceStartAuto:
+		return "Auto"
+	case ServiceStartDemand:
+		return "Demand"
+	case ServiceStartDisabled:
+		return "Disabled"
+	default:
+		return "Unknown"
+	}
+}
+
+type ServiceState uint32
+
+const (
+	ServiceStateStopped ServiceState = iota + 1
+	ServiceStateStartPending
+	ServiceStateStopPending
+	ServiceStateRunning
+	ServiceStateContinuePending
+	ServiceStatePausePending
+	ServiceStatePaused
+)
+
+func (s ServiceState) String() string {
+	switch s {
+	case ServiceStateStopped:
+		return "Stopped"
+	case ServiceStateStartPending:
+		return "StartPending"
+	case ServiceStateStopPending:
+		return "StopPending"
+	case ServiceStateRunning:
+		return "Running"
+	case ServiceStateContinuePending:
+		return "ContinuePending"
+	case ServiceStatePausePending:
+		return "PausePending"
+	case ServiceStatePaused:
+		return "Paused"
+	default:
+		return "Unknown"
+	}
+}
+
+// Service types (SERVICE_STATUS.dwServiceType bit flags)
+const (
+	ServiceTypeKernelDriver       = 0x00000001
+	ServiceTypeFileSystemDriver   = 0x00000002
+	ServiceTypeWin32OwnProcess    = 0x00000010
+	ServiceTypeWin32ShareProcess  = 0x00000020
+	ServiceTypeUserService        = 0x00000040
+	ServiceTypeInteractiveProcess = 0x00000100
+)
+
+type ServiceEntry struct {
+	VaObj                   uint64
+	Ordinal                 uint32
+	StartType               ServiceStartType
+	ServiceType             uint32
+	State                   ServiceState
+	ControlsAccepted        uint32
+	Win32ExitCode           uint32
+	ServiceSpecificExitCode uint32
+	CheckPoint              uint32
+	WaitHint                uint32
+	Name                    string
+	DisplayName             string
+	Path                    string
+	UserType                string
+	UserAccount             string
+	ImagePath               string
+	PID                     uint32
+}
+
+type Service struct {
+	Version   uint32
+	MultiText []string
+	Entries   []ServiceEntry
+}
+
+// ByPID groups the services by hosting process. Services without a process are
+// grouped under PID 0.
+func (s *Service) ByPID() map[uint32][]ServiceEntry {
+	result := make(map[uint32][]ServiceEntry)
+	for _, entry := range s.Entries {
+		result[entry.PID] = append(result[entry.PID], entry)
+	}
+	return result
+}
+
+func newServiceEntry(cEntry *C.VMMDLL_MAP_SERVICEENTRY) ServiceEntry {
+	// SERVICE_STATUS is 7 DWORDs, the string pointer unions that follow are padded to
+	// pointer alignment
+	align := unsafe.Alignof(uintptr(0))
+	offset := (unsafe.Offsetof(cEntry.ServiceStatus) + unsafe.Sizeof(cEntry.ServiceStatus) + align - 1) &^ (align - 1)
+	stringsPtr := unsafe.Add(unsafe.Pointer(cEntry), offset)
+	text := func(i uintptr) string {
+		return readCString(unsafe.Pointer(uintptr(stringsPtr) + i*unsafe.Sizeof(uint64(0))))
+	}
+
+	return ServiceEntry{
+		VaObj:                   uint64(cEntry.vaObj),
+		Ordinal:                 uint32(cEntry.dwOrdinal),
+		StartType:               ServiceStartType(cEntry.dwStartType),
+		ServiceType:             uint32(cEntry.ServiceStatus.dwServiceType),
+		State:                   ServiceState(cEntry.ServiceStatus.dwCurrentState),
+		ControlsAccepted:        uint32(cEntry.ServiceStatus.dwControlsAccepted),
+		Win32ExitCode:           uint32(cEntry.ServiceStatus.dwWin32ExitCode),
+		ServiceSpecificExitCode: uint32(cEntry.ServiceStatus.dwServiceSpecificExitCode),
+		CheckPoint:              uint32(cEntry.ServiceStatus.dwCheckPoint),
+		WaitHint:                uint32(cEntry.ServiceStatus.dwWaitHint),
+		Name:                    text(0),
+		DisplayName:             text(1),
+		Path:                    text(2),
+		UserType:                text(3),
+		UserAccount:             text(4),
+		ImagePath:               text(5),
+		PID:                     uint32(cEntry.dwPID),
+	}
+}
+
+func newService(cService *C.VMMDLL_MAP_SERVICE) Service {
+	service := Service{
+		Version: uint32(cService.dwVersion),
+	}
+
+	if cService.pbMultiText != nil && cService.cbMultiText > 0 {
+		service.MultiText = multiString(C.GoBytes(unsafe.Pointer(cService.pbMultiText), C.int(cService.cbMultiText)))
+	}
+
+	count := int(cService.cMap)
+	service.Entries = make([]ServiceEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_SERVICEENTRY](afterDWORD(unsafe.Pointer(&cService.cMap)), count) {
+		service.Entries[i] = newServiceEntry(cEntry)
+	}
+
+	return service
+}
+
+func (vmm *Vmm) getServiceMap() (*Service, error) {
+	var cServiceMap C.PVMMDLL_MAP_SERVICE
+	success := C.VMMDLL_Map_GetServicesU(C.VMM_HANDLE(vmm.handle), &cServiceMap)
+
+	if success == 0 || cServiceMap == nil {
+		return nil, fmt.Errorf("failed to get service map")
+	}
+
+	defer freeMemory(C.PVOID(cServiceMap))
+
+	if cServiceMap.dwVersion != MapServiceVersion {
+		return nil, ErrUnsupportedServiceVersion
+	}
+
+	service := newService(cServiceMap)
+
+	return &service, nil
+}
+
+func (vmm *Vmm) GetServiceMap(ctx context.Context) (*Service, error) {
+	resultChan := make(chan struct {
+		service *Service
+		err     error
+	}, 1)
+
+	go func() {
+		service, err := vmm.getServiceMap()
+		resultChan <- struct {
+			service *Service
+			err     error
+		}{service, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.service, result.err
+	}
+}
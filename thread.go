@@ -0,0 +1,330 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// ThreadState mirrors the Windows KTHREAD_STATE enumeration.
+type ThreadState uint8
+
+const (
+	ThreadStateInitialized ThreadState = iota
+	ThreadStateReady
+	ThreadStateRunning
+	ThreadStateStandby
+	ThreadStateTerminated
+	ThreadStateWaiting
+	ThreadStateTransition
+	ThreadStateDeferredReady
+	ThreadStateGateWaitObsolete
+	ThreadStateWaitingForProcessInSwap
+)
+
+func (t ThreadState) String() string {
+	switch t {
+	case ThreadStateInitialized:
+		return "Initialized"
+	case ThreadStateReady:
+		return "Ready"
+	case ThreadStateRunning:
+		return "Running"
+	case ThreadStateStandby:
+		return "Standby"
+	case ThreadStateTerminated:
+		return "Terminated"
+	case ThreadStateWaiting:
+		return "Waiting"
+	case ThreadStateTransition:
+		return "Transition"
+	case ThreadStateDeferredReady:
+		return "DeferredReady"
+	case ThreadStateGateWaitObsolete:
+		return "GateWaitObsolete"
+	case ThreadStateWaitingForProcessInSwap:
+		return "WaitingForProcessInSwap"
+	default:
+		return "Unknown"
+	}
+}
+
+// ThreadWaitReason mirrors the Windows KWAIT_REASON enumeration.
+type ThreadWaitReason uint8
+
+var threadWaitReasonNames = []string{
+	"Executive", "FreePage", "PageIn", "PoolAllocation", "DelayExecution", "Suspended", "UserRequest",
+	"WrExecutive", "WrFreePage", "WrPageIn", "WrPoolAllocation", "WrDelayExecution", "WrSuspended",
+	"WrUserRequest", "WrSpare0", "WrQueue", "WrLpcReceive", "WrLpcReply", "WrVirtualMemory", "WrPageOut",
+	"WrRendezvous", "WrKeyedEvent", "WrTerminated", "WrProcessInSwap", "WrCpuRateControl",
+	"WrCalloutStack", "WrKernel", "WrResource", "WrPushLock", "WrMutex", "WrQuantumEnd",
+	"WrDispatchInt", "WrPreempted", "WrYieldExecution", "WrFastMutex", "WrGuardedMutex", "WrRundown",
+	"WrAlertByThreadId", "WrDeferredPreempt", "WrPhysicalFault", "WrIoRing", "WrMdlCache",
+}
+
+func (w ThreadWaitReason) String() string {
+	if int(w) < len(threadWaitReasonNames) {
+		return threadWaitReasonNames[w]
+	}
+	return "Unknown"
+}
+
+type ThreadEntry struct {
+	TID                  uint32
+	PID                  uint32
+	ExitStatus           uint32
+	State                ThreadState
+	Running              bool
+	Priority             uint8
+	BasePriority         uint8
+	VaETHREAD            uint64
+	VaTeb                uint64
+	CreateTime           time.Time
+	ExitTime             time.Time
+	VaStartAddress       uint64
+	VaWin32StartAddress  uint64
+	VaStackBaseUser      uint64
+	VaStackLimitUser     uint64
+	VaStackBaseKernel    uint64
+	VaStackLimitKernel   uint64
+	VaTrapFrame          uint64
+	VaRIP                uint64
+	VaRSP                uint64
+	Affinity             uint64
+	UserTime             uint32
+	KernelTime           uint32
+	SuspendCount         uint8
+	WaitReason           ThreadWaitReason
+	VaImpersonationToken uint64
+}
+
+type Thread struct {
+	Version uint32
+	Entries []ThreadEntry
+}
+
+func newThreadEntry(cEntry *C.VMMDLL_MAP_THREADENTRY) ThreadEntry {
+	return ThreadEntry{
+		TID:                  uint32(cEntry.dwTID),
+		PID:                  uint32(cEntry.dwPID),
+		ExitStatus:           uint32(cEntry.dwExitStatus),
+		State:                ThreadState(cEntry.bState),
+		Running:              cEntry.bRunning != 0,
+		Priority:             uint8(cEntry.bPriority),
+		BasePriority:         uint8(cEntry.bBasePriority),
+		VaETHREAD:            uint64(cEntry.vaETHREAD),
+		VaTeb:                uint64(cEntry.vaTeb),
+		CreateTime:           fileTimeToTime(uint64(cEntry.ftCreateTime)),
+		ExitTime:             fileTimeToTime(uint64(cEntry.ftExitTime)),
+		VaStartAddress:       uint64(cEntry.vaStartAddress),
+		VaWin32StartAddress:  uint64(cEntry.vaWin32StartAddress),
+		VaStackBaseUser:      uint64(cEntry.vaStackBaseUser),
+		VaStackLimitUser:     uint64(cEntry.vaStackLimitUser),
+		VaStackBaseKernel:    uint64(cEntry.vaStackBaseKernel),
+		VaStackLimitKernel:   uint64(cEntry.vaStackLimitKernel),
+		VaTrapFrame:          uint64(cEntry.vaTrapFrame),
+		VaRIP:                uint64(cEntry.vaRIP),
+		VaRSP:                uint64(cEntry.vaRSP),
+		Affinity:             uint64(cEntry.qwAffinity),
+		UserTime:             uint32(cEntry.dwUserTime),
+		KernelTime:           uint32(cEntry.dwKernelTime),
+		SuspendCount:         uint8(cEntry.bSuspendCount),
+		WaitReason:           ThreadWaitReason(cEntry.bWaitReason),
+		VaImpersonationToken: uint64(cEntry.vaImpersonationToken),
+	}
+}
+
+func newThread(cThread *C.VMMDLL_MAP_THREAD) Thread {
+	count := int(cThread.cMap)
+	entries := make([]ThreadEntry, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_THREADENTRY](afterDWORD(unsafe.Pointer(&cThread.cMap)), count) {
+		entries[i] = newThreadEntry(cEntry)
+	}
+
+	return Thread{
+		Version: uint32(cThread.dwVersion),
+		Entries: entries,
+	}
+}
+
+func (vmm *Vmm) getProcessMapThread(pid uint32) (*Thread, error) {
+	var cThreadMap C.PVMMDLL_MAP_THREAD
+	success := C.VMMDLL_Map_GetThread(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), &cThreadMap)
+
+	if success == 0 || cThreadMap == nil {
+		return nil, fmt.Errorf("failed to get thread map for process %d", pid)
+	}
+
+	defer freeMemory(C.PVOID(cThreadMap))
+
+	if cThreadMap.dwVersion != MapThreadVersion {
+		return nil, ErrUnsupportedThreadVersion
+	}
+
+	thread := newThread(cThreadMap)
+
+	return &thread, nil
+}
+
+func (vmm *Vmm) GetProcessMapThread(ctx context.Context, pid uint32) (*Thread, error) {
+	resultChan := make(chan struct {
+		thread *Thread
+		err    error
+	}, 1)
+
+	go func() {
+		thread, err := vmm.getProcessMapThread(pid)
+		resultChan <- struct {
+			thread *Thread
+			err    error
+		}{thread, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.thread, result.err
+	}
+}
+
+type CallstackFrame struct {
+	Index        uint32
+	RegPresent   bool
+	VaRetAddr    uint64
+	VaRSP        uint64
+	VaBaseSP     uint64
+	Displacement uint32
+	Module       string
+	Function     string
+}
+
+// Symbol returns the frame location as module!function+0xdisp.
+func (f CallstackFrame) Symbol() string {
+	switch {
+	case f.Module == "":
+		return fmt.Sprintf("0x%x", f.VaRetAddr)
+	case f.Function == "":
+		return fmt.Sprintf("%s+0x%x", f.Module, f.Displacement)
+	default:
+		return fmt.Sprintf("%s!%s+0x%x", f.Module, f.Function, f.Displacement)
+	}
+}
+
+type Callstack struct {
+	Version   uint32
+	PID       uint32
+	TID       uint32
+	Text      string
+	MultiText []string
+	Frames    []CallstackFrame
+}
+
+// String formats the call stack in the layout of the WinDbg 'k' command.
+func (c *Callstack) String() string {
+	var sb strings.Builder
+
+	sb.WriteString(" # Child-SP          RetAddr               Call Site\n")
+	for i, frame := range c.Frames {
+		// RetAddr is where this frame returns to, i.e. the location of the caller
+		var retAddr uint64
+		if i+1 < len(c.Frames) {
+			retAddr = c.Frames[i+1].VaRetAddr
+		}
+		fmt.Fprintf(&sb, "%02x %s %s     %s\n", frame.Index, windbgAddress(frame.VaRSP), windbgAddress(retAddr), frame.Symbol())
+	}
+
+	return sb.String()
+}
+
+func windbgAddress(va uint64) string {
+	return fmt.Sprintf("%08x`%08x", va>>32, va&0xFFFFFFFF)
+}
+
+func newCallstackFrame(cEntry *C.VMMDLL_MAP_THREAD_CALLSTACKENTRY) CallstackFrame {
+	uszModulePtr := afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.cbDisplacement), unsafe.Sizeof(cEntry.cbDisplacement))
+	uszFunctionPtr := unsafe.Pointer(uintptr(uszModulePtr) + unsafe.Sizeof(uintptr(0)))
+
+	return CallstackFrame{
+		Index:        uint32(cEntry.i),
+		RegPresent:   cEntry.fRegPresent != 0,
+		VaRetAddr:    uint64(cEntry.vaRetAddr),
+		VaRSP:        uint64(cEntry.vaRSP),
+		VaBaseSP:     uint64(cEntry.vaBaseSP),
+		Displacement: uint32(cEntry.cbDisplacement),
+		Module:       C.GoString(*(**C.char)(uszModulePtr)),
+		Function:     C.GoString(*(**C.char)(uszFunctionPtr)),
+	}
+}
+
+func newCallstack(cCallstack *C.VMMDLL_MAP_THREAD_CALLSTACK) Callstack {
+	uszTextPtr := afterField(unsafe.Pointer(cCallstack), unsafe.Offsetof(cCallstack.cbText), unsafe.Sizeof(cCallstack.cbText))
+
+	callstack := Callstack{
+		Version: uint32(cCallstack.dwVersion),
+		PID:     uint32(cCallstack.dwPID),
+		TID:     uint32(cCallstack.dwTID),
+		Text:    C.GoString(*(**C.char)(uszTextPtr)),
+	}
+
+	if cCallstack.pbMultiText != nil && cCallstack.cbMultiText > 0 {
+		callstack.MultiText = multiString(C.GoBytes(unsafe.Pointer(cCallstack.pbMultiText), C.int(cCallstack.cbMultiText)))
+	}
+
+	count := int(cCallstack.cMap)
+	callstack.Frames = make([]CallstackFrame, count)
+
+	for i, cEntry := range cArray[C.VMMDLL_MAP_THREAD_CALLSTACKENTRY](afterDWORD(unsafe.Pointer(&cCallstack.cMap)), count) {
+		callstack.Frames[i] = newCallstackFrame(cEntry)
+	}
+
+	return callstack
+}
+
+func (vmm *Vmm) getThreadCallstack(pid uint32, tid uint32, flags VMMFlag) (*Callstack, error) {
+	var cCallstack C.PVMMDLL_MAP_THREAD_CALLSTACK
+	success := C.VMMDLL_Map_GetThread_CallstackU(C.VMM_HANDLE(vmm.handle), C.DWORD(pid), C.DWORD(tid), C.DWORD(flags), &cCallstack)
+
+	if success == 0 || cCallstack == nil {
+		return nil, fmt.Errorf("failed to get callstack for process %d, thread %d", pid, tid)
+	}
+
+	defer freeMemory(C.PVOID(cCallstack))
+
+	if cCallstack.dwVersion != MapThreadCallstackVersion {
+		return nil, ErrUnsupportedThreadCallstackVersion
+	}
+
+	callstack := newCallstack(cCallstack)
+
+	return &callstack, nil
+}
+
+func (vmm *Vmm) GetThreadCallstack(ctx context.Context, pid uint32, tid uint32, flags VMMFlag) (*Callstack, error) {
+	resultChan := make(chan struct {
+		callstack *Callstack
+		err       error
+	}, 1)
+
+	go func() {
+		callstack, err := vmm.getThreadCallstack(pid, tid, flags)
+		resultChan <- struct {
+			callstack *Callstack
+			err       error
+		}{callstack, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.callstack, result.err
+	}
+}
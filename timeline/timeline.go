@@ -0,0 +1,222 @@
+package timeline
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"io/fs"
+	"iter"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timeline files in /forensic/timeline/.
+const (
+	FileAll      = "timeline_all.txt"
+	FileNTFS     = "timeline_ntfs.txt"
+	FileProcess  = "timeline_process.txt"
+	FileNet      = "timeline_net.txt"
+	FileRegistry = "timeline_registry.txt"
+	FileThread   = "timeline_thread.txt"
+	FileTask     = "timeline_task.txt"
+	FileWeb      = "timeline_web.txt"
+)
+
+// Dir is the timeline directory relative to the VFS root.
+const Dir = "forensic/timeline"
+
+const timeLayout = "2006-01-02 15:04:05 MST"
+
+// maxLineSize bounds a single timeline line, descriptions may contain long paths.
+const maxLineSize = 1 << 20
+
+// Source is the timeline type column, e.g. "PROC" or "NTFS".
+type Source string
+
+const (
+	SourceProcess  Source = "PROC"
+	SourceNTFS     Source = "NTFS"
+	SourceRegistry Source = "REG"
+	SourceNet      Source = "NET"
+	SourceThread   Source = "THRD"
+	SourceTask     Source = "TASK"
+	SourceWeb      Source = "WEB"
+)
+
+// Action is the timeline action column.
+type Action string
+
+const (
+	ActionCreate Action = "CRE"
+	ActionModify Action = "MOD"
+	ActionRead   Action = "RD"
+	ActionDelete Action = "DEL"
+	ActionEntry  Action = "ENTRY"
+)
+
+var ErrInvalidLine = errors.New("invalid timeline line")
+
+type Event struct {
+	Time        time.Time
+	Source      Source
+	Action      Action
+	PID         uint32
+	Value32     uint32
+	Value64     uint64
+	Description string
+}
+
+// ParseLine parses a single timeline line:
+//
+//	2023-01-15 12:34:56 UTC  PROC CRE    3328     3660 ffffa08e23c6c080 \Device\...\svchost.exe
+func ParseLine(line string) (Event, error) {
+	var event Event
+	var fields [8]string
+
+	rest := line
+	for i := range fields {
+		fields[i], rest = nextField(rest)
+		if fields[i] == "" {
+			return event, ErrInvalidLine
+		}
+	}
+
+	t, err := time.Parse(timeLayout, fields[0]+" "+fields[1]+" "+fields[2])
+	if err != nil {
+		return event, ErrInvalidLine
+	}
+
+	pid, err := strconv.ParseUint(fields[5], 10, 32)
+	if err != nil {
+		return event, ErrInvalidLine
+	}
+
+	// Value32 is written in decimal, Value64 in hex
+	value32, err := strconv.ParseUint(fields[6], 10, 32)
+	if err != nil {
+		return event, ErrInvalidLine
+	}
+
+	value64, err := strconv.ParseUint(fields[7], 16, 64)
+	if err != nil {
+		return event, ErrInvalidLine
+	}
+
+	event.Time = t.UTC()
+	event.Source = Source(fields[3])
+	event.Action = Action(fields[4])
+	event.PID = uint32(pid)
+	event.Value32 = uint32(value32)
+	event.Value64 = value64
+	event.Description = strings.TrimSpace(rest)
+	return event, nil
+}
+
+// nextField returns the next space separated field and the remainder of s.
+func nextField(s string) (string, string) {
+	s = strings.TrimLeft(s, " \t")
+	end := strings.IndexAny(s, " \t")
+	if end == -1 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// Reader streams events from a timeline file. Header, separator and other lines that
+// don't parse as events are skipped.
+type Reader struct {
+	scanner *bufio.Scanner
+	closer  io.Closer
+	event   Event
+	err     error
+
+	from time.Time
+	to   time.Time
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	reader := &Reader{scanner: scanner}
+	if closer, ok := r.(io.Closer); ok {
+		reader.closer = closer
+	}
+	return reader
+}
+
+// Open opens a timeline file, e.g. FileAll, from a VFS file system such as vfs.FS.
+// Forensic mode must have completed for the files to exist.
+func Open(fsys fs.FS, file string) (*Reader, error) {
+	f, err := fsys.Open(path.Join(Dir, file))
+	if err != nil {
+		return nil, err
+	}
+	return NewReader(f), nil
+}
+
+// Between restricts the reader to events with from <= Time < to. A zero from or to
+// leaves that side of the range open.
+func (r *Reader) Between(from, to time.Time) *Reader {
+	r.from = from
+	r.to = to
+	return r
+}
+
+// Next advances to the next event, it returns false at the end of the file or on error.
+func (r *Reader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	for r.scanner.Scan() {
+		event, err := ParseLine(r.scanner.Text())
+		if err != nil {
+			continue
+		}
+		if !r.from.IsZero() && event.Time.Before(r.from) {
+			continue
+		}
+		if !r.to.IsZero() && !event.Time.Before(r.to) {
+			continue
+		}
+
+		r.event = event
+		return true
+	}
+
+	r.err = r.scanner.Err()
+	return false
+}
+
+func (r *Reader) Event() Event {
+	return r.event
+}
+
+// Err returns the first read error, if any.
+func (r *Reader) Err() error {
+	return r.err
+}
+
+// All iterates over the remaining events. A read error is yielded as the last element.
+func (r *Reader) All() iter.Seq2[Event, error] {
+	return func(yield func(Event, error) bool) {
+		for r.Next() {
+			if !yield(r.event, nil) {
+				return
+			}
+		}
+		if r.err != nil {
+			yield(Event{}, r.err)
+		}
+	}
+}
+
+func (r *Reader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
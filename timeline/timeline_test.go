@@ -0,0 +1,162 @@
+package timeline
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testTimeline = `   Time                   Type Action   PID    Value32    Value64     Text
+---------------------------------------------------------------------------------------------
+2023-01-15 12:34:56 UTC  PROC CRE    3328     3660 ffffa08e23c6c080 \Device\HarddiskVolume3\Windows\System32\svchost.exe
+2023-01-15 12:35:00 UTC  NTFS MOD       0        0      1a2b3c4d5e6 \Windows\Temp\a b.txt
+2023-01-15 12:40:00 UTC  NET  CRE    4000     443 ffffa08e24001010 TCP 10.0.0.2:49712 -> 10.0.0.1:443
+not a timeline line
+2023-01-15 13:00:00 UTC  REG  MOD       4        0                0 HKLM\SYSTEM\ControlSet001
+`
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Event
+		wantErr bool
+	}{
+		{
+			name: "process",
+			line: `2023-01-15 12:34:56 UTC  PROC CRE    3328     3660 ffffa08e23c6c080 \Device\HarddiskVolume3\Windows\System32\svchost.exe`,
+			want: Event{
+				Time:        time.Date(2023, 1, 15, 12, 34, 56, 0, time.UTC),
+				Source:      SourceProcess,
+				Action:      ActionCreate,
+				PID:         3328,
+				Value32:     3660,
+				Value64:     0xffffa08e23c6c080,
+				Description: `\Device\HarddiskVolume3\Windows\System32\svchost.exe`,
+			},
+		},
+		{
+			name: "description with spaces",
+			line: `2023-01-15 12:35:00 UTC  NTFS MOD       0        0      1a2b3c4d5e6 \Windows\Temp\a b.txt  `,
+			want: Event{
+				Time:        time.Date(2023, 1, 15, 12, 35, 0, 0, time.UTC),
+				Source:      SourceNTFS,
+				Action:      ActionModify,
+				Value64:     0x1a2b3c4d5e6,
+				Description: `\Windows\Temp\a b.txt`,
+			},
+		},
+		{
+			name: "net",
+			line: `2023-01-15 12:40:00 UTC  NET  CRE    4000     443 ffffa08e24001010 TCP`,
+			want: Event{
+				Time:        time.Date(2023, 1, 15, 12, 40, 0, 0, time.UTC),
+				Source:      SourceNet,
+				Action:      ActionCreate,
+				PID:         4000,
+				Value32:     443,
+				Value64:     0xffffa08e24001010,
+				Description: "TCP",
+			},
+		},
+		{name: "empty", line: "", wantErr: true},
+		{name: "header", line: "   Time                   Type Action   PID    Value32    Value64     Text", wantErr: true},
+		{name: "separator", line: strings.Repeat("-", 80), wantErr: true},
+		{name: "truncated", line: "2023-01-15 12:34:56 UTC  PROC CRE    3328", wantErr: true},
+		{name: "invalid pid", line: "2023-01-15 12:34:56 UTC  PROC CRE    x     3660 0 text", wantErr: true},
+		{name: "hex value32", line: "2023-01-15 12:40:00 UTC  NET  CRE    4000     1bb ffffa08e24001010 TCP", wantErr: true},
+		{name: "invalid time", line: "2023-13-15 12:34:56 UTC  PROC CRE    3328     3660 0 text", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLine(tt.line)
+			if tt.wantErr {
+				if err != ErrInvalidLine {
+					t.Fatalf("ParseLine error = %v, want ErrInvalidLine", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLine = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func readSources(t *testing.T, r *Reader) []Source {
+	t.Helper()
+
+	var sources []Source
+	for event, err := range r.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources = append(sources, event.Source)
+	}
+	return sources
+}
+
+func TestReaderSkipsNonEvents(t *testing.T) {
+	got := readSources(t, NewReader(strings.NewReader(testTimeline)))
+	want := []Source{SourceProcess, SourceNTFS, SourceNet, SourceRegistry}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+func TestReaderBetween(t *testing.T) {
+	at := func(hour, min, sec int) time.Time {
+		return time.Date(2023, 1, 15, hour, min, sec, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []Source
+	}{
+		{name: "open", want: []Source{SourceProcess, SourceNTFS, SourceNet, SourceRegistry}},
+		{name: "from inclusive", from: at(12, 35, 0), want: []Source{SourceNTFS, SourceNet, SourceRegistry}},
+		{name: "to exclusive", to: at(12, 40, 0), want: []Source{SourceProcess, SourceNTFS}},
+		{name: "both", from: at(12, 35, 0), to: at(13, 0, 0), want: []Source{SourceNTFS, SourceNet}},
+		{name: "empty range", from: at(12, 36, 0), to: at(12, 39, 0), want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := readSources(t, NewReader(strings.NewReader(testTimeline)).Between(tt.from, tt.to))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("sources = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	fsys := fstest.MapFS{
+		Dir + "/" + FileAll: &fstest.MapFile{Data: []byte(testTimeline)},
+	}
+
+	r, err := Open(fsys, FileAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if !r.Next() {
+		t.Fatalf("Next = false, err %v", r.Err())
+	}
+	if got := r.Event().PID; got != 3328 {
+		t.Errorf("first PID = %d, want 3328", got)
+	}
+
+	if _, err := Open(fsys, FileNTFS); err == nil {
+		t.Error("Open of a missing file succeeded")
+	}
+}
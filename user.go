@@ -0,0 +1,90 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"fmt"
+	"unsafe"
+)
+
+type UserEntry struct {
+	Name      string
+	SID       string
+	VaRegHive uint64
+}
+
+type User struct {
+	Version   uint32
+	MultiText []string
+	Entries   []UserEntry
+}
+
+func newUserEntry(cEntry *C.VMMDLL_MAP_USERENTRY) UserEntry {
+	return UserEntry{
+		Name:      readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry._FutureUse1), unsafe.Sizeof(cEntry._FutureUse1))),
+		SID:       readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.vaRegHive), unsafe.Sizeof(cEntry.vaRegHive))),
+		VaRegHive: uint64(cEntry.vaRegHive),
+	}
+}
+
+func newUser(cUser *C.VMMDLL_MAP_USER) User {
+	user := User{
+		Version: uint32(cUser.dwVersion),
+	}
+
+	if cUser.pbMultiText != nil && cUser.cbMultiText > 0 {
+		user.MultiText = multiString(C.GoBytes(unsafe.Pointer(cUser.pbMultiText), C.int(cUser.cbMultiText)))
+	}
+
+	count := int(cUser.cMap)
+	user.Entries = make([]UserEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_USERENTRY](afterDWORD(unsafe.Pointer(&cUser.cMap)), count) {
+		user.Entries[i] = newUserEntry(cEntry)
+	}
+
+	return user
+}
+
+func (vmm *Vmm) getUserMap() (*User, error) {
+	var cUserMap C.PVMMDLL_MAP_USER
+	success := C.VMMDLL_Map_GetUsersU(C.VMM_HANDLE(vmm.handle), &cUserMap)
+
+	if success == 0 || cUserMap == nil {
+		return nil, fmt.Errorf("failed to get user map")
+	}
+
+	defer freeMemory(C.PVOID(cUserMap))
+
+	if cUserMap.dwVersion != MapUserVersion {
+		return nil, ErrUnsupportedUserVersion
+	}
+
+	user := newUser(cUserMap)
+
+	return &user, nil
+}
+
+func (vmm *Vmm) GetUserMap(ctx context.Context) (*User, error) {
+	resultChan := make(chan struct {
+		user *User
+		err  error
+	}, 1)
+
+	go func() {
+		user, err := vmm.getUserMap()
+		resultChan <- struct {
+			user *User
+			err  error
+		}{user, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.user, result.err
+	}
+}
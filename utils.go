@@ -2,6 +2,7 @@ package go_memprocfs
 
 import (
 	"bytes"
+	"time"
 	"unsafe"
 )
 
@@ -54,6 +55,38 @@ func afterField(base unsafe.Pointer, fieldOffset uintptr, fieldSize uintptr) uns
 	return unsafe.Pointer(uintptr(base) + fieldOffset + fieldSize)
 }
 
+// fileTimeToTime converts a Windows FILETIME (100ns intervals since 1601-01-01 UTC)
+// to time.Time. A zero FILETIME is returned as the zero time.Time.
+func fileTimeToTime(ft uint64) time.Time {
+	if ft == 0 {
+		return time.Time{}
+	}
+	const epochDiff = 116444736000000000 // 1601-01-01 to 1970-01-01 in 100ns intervals
+	const intervalsPerSecond = 10000000
+	// seconds and nanoseconds are computed separately, nanoseconds since 1970 overflow
+	// int64 after 2262 which garbage values read from memory easily exceed
+	if ft >= epochDiff {
+		d := ft - epochDiff
+		return time.Unix(int64(d/intervalsPerSecond), int64(d%intervalsPerSecond)*100).UTC()
+	}
+	d := epochDiff - ft
+	return time.Unix(-int64(d/intervalsPerSecond), -int64(d%intervalsPerSecond)*100).UTC()
+}
+
+// timeToFileTime converts t to a Windows FILETIME. The zero time.Time is returned as 0.
+func timeToFileTime(t time.Time) uint64 {
+	if t.IsZero() {
+		return 0
+	}
+	const epochDiff = 116444736000000000
+	return uint64(t.UnixNano()/100 + epochDiff)
+}
+
+// poolTagString converts a pool tag DWORD into its 4-character text representation.
+func poolTagString(tag uint32) string {
+	return string([]byte{byte(tag), byte(tag >> 8), byte(tag >> 16), byte(tag >> 24)})
+}
+
 /**
 todo: VMMDLL_Log
 */
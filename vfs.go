@@ -1,24 +1,234 @@
 package go_memprocfs
 
 /*
-todo:
-VMMDLL_VfsList_AddFile
-VMMDLL_VfsList_AddDirectory
-VMMDLL_VfsList_IsHandleValid
-VMMDLL_VfsListU
-VMMDLL_VfsListBlobU
-VMMDLL_VfsReadU
-VMMDLL_VfsWriteU
-
-VMMDLL_UtilVfsReadFile_FromPBYTE(_In_ PBYTE pbFile, _In_ ULONG64 cbFile, _Out_writes_to_(cb, *pcbRead) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbRead, _In_ ULONG64 cbOffset);
-VMMDLL_UtilVfsReadFile_FromQWORD(_In_ ULONG64 qwValue, _Out_writes_to_(cb, *pcbRead) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbRead, _In_ ULONG64 cbOffset, _In_ BOOL fPrefix);
-VMMDLL_UtilVfsReadFile_FromDWORD(_In_ DWORD dwValue, _Out_writes_to_(cb, *pcbRead) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbRead, _In_ ULONG64 cbOffset, _In_ BOOL fPrefix);
-VMMDLL_UtilVfsReadFile_FromBOOL(_In_ BOOL fValue, _Out_writes_to_(cb, *pcbRead) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbRead, _In_ ULONG64 cbOffset);
-VMMDLL_UtilVfsWriteFile_BOOL(_Inout_ PBOOL pfTarget, _In_reads_(cb) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbWrite, _In_ ULONG64 cbOffset);
-VMMDLL_UtilVfsWriteFile_DWORD(_Inout_ PDWORD pdwTarget, _In_reads_(cb) PBYTE pb, _In_ DWORD cb, _Out_ PDWORD pcbWrite, _In_ ULONG64 cbOffset, _In_ DWORD dwMinAllow);
-VMMDLL_InitializePlugins
-
-
-forensic:
-VMMDLL_ForensicFileAppend
+#include "vmmdll.h"
 */
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// NTSTATUS values returned by the VFS functions
+const (
+	VfsStatusSuccess              = 0x00000000
+	VfsStatusUnsuccessful         = 0xC0000001
+	VfsStatusEndOfFile            = 0xC0000011
+	VfsStatusFileInvalid          = 0xC0000098
+	VfsStatusFileSystemLimitation = 0xC0000427
+)
+
+// cbFileSize value marking a directory in VMMDLL_VFS_FILELISTBLOB_ENTRY
+const vfsFileListBlobDirectory = ^uint64(0)
+
+var ErrVfsInvalidPath = errors.New("invalid VFS path: not valid UTF-8")
+
+type VfsEntry struct {
+	Name           string
+	Size           uint64
+	IsDir          bool
+	Compressed     bool
+	CreationTime   time.Time
+	LastAccessTime time.Time
+	LastWriteTime  time.Time
+}
+
+// vfsFileListBlobEntry mirrors VMMDLL_VFS_FILELISTBLOB_ENTRY whose fields are hidden
+// in anonymous unions.
+type vfsFileListBlobEntry struct {
+	name           *C.char
+	fileSize       uint64
+	exVersion      uint32
+	exCompressed   uint32
+	creationTime   uint64
+	lastAccessTime uint64
+	lastWriteTime  uint64
+}
+
+// vfsPath converts a slash separated UTF-8 path into the backslash separated,
+// rooted form expected by the VMMDLL_Vfs* functions.
+func vfsPath(path string) (string, error) {
+	if !utf8.ValidString(path) {
+		return "", ErrVfsInvalidPath
+	}
+	path = strings.ReplaceAll(path, "/", "\\")
+	if !strings.HasPrefix(path, "\\") {
+		path = "\\" + path
+	}
+	return path, nil
+}
+
+func newVfsEntry(cEntry *vfsFileListBlobEntry) VfsEntry {
+	entry := VfsEntry{
+		Name:           C.GoString(cEntry.name),
+		IsDir:          cEntry.fileSize == vfsFileListBlobDirectory,
+		Compressed:     cEntry.exCompressed != 0,
+		CreationTime:   fileTimeToTime(cEntry.creationTime),
+		LastAccessTime: fileTimeToTime(cEntry.lastAccessTime),
+		LastWriteTime:  fileTimeToTime(cEntry.lastWriteTime),
+	}
+	if !entry.IsDir {
+		entry.Size = cEntry.fileSize
+	}
+	return entry
+}
+
+func (vmm *Vmm) vfsList(path string) ([]VfsEntry, error) {
+	uszPath, err := vfsPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cPath := C.CString(uszPath)
+	defer C.free(unsafe.Pointer(cPath))
+
+	cBlob := C.VMMDLL_VfsListBlobU(C.VMM_HANDLE(vmm.handle), cPath)
+	if cBlob == nil {
+		// the list fails for paths that don't exist
+		return nil, fmt.Errorf("VMMDLL_VfsListBlobU: failed to list %s: %w", path, fs.ErrNotExist)
+	}
+
+	defer freeMemory(C.PVOID(cBlob))
+
+	if cBlob.dwVersion != C.VMMDLL_VFS_FILELISTBLOB_VERSION {
+		return nil, fmt.Errorf("VMMDLL_VfsListBlobU: unsupported version 0x%x", uint32(cBlob.dwVersion))
+	}
+
+	count := int(cBlob.cFileEntry)
+	entriesPtr := afterField(unsafe.Pointer(cBlob), unsafe.Offsetof(cBlob._FutureUse), unsafe.Sizeof(cBlob._FutureUse))
+
+	result := make([]VfsEntry, count)
+	for i, cEntry := range cArray[vfsFileListBlobEntry](entriesPtr, count) {
+		result[i] = newVfsEntry(cEntry)
+	}
+
+	return result, nil
+}
+
+// VfsList lists the directory at path, e.g. "/sys" or "/name/explorer.exe-1234".
+func (vmm *Vmm) VfsList(ctx context.Context, path string) ([]VfsEntry, error) {
+	resultChan := make(chan struct {
+		entries []VfsEntry
+		err     error
+	}, 1)
+
+	go func() {
+		entries, err := vmm.vfsList(path)
+		resultChan <- struct {
+			entries []VfsEntry
+			err     error
+		}{entries, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.entries, result.err
+	}
+}
+
+func (vmm *Vmm) vfsRead(path string, offset uint64, size uint32) ([]byte, error) {
+	uszPath, err := vfsPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		return []byte{}, nil
+	}
+
+	cPath := C.CString(uszPath)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var bytesRead C.DWORD
+	buf := make([]byte, size)
+	status := C.VMMDLL_VfsReadU(C.VMM_HANDLE(vmm.handle), cPath, (*C.BYTE)(unsafe.Pointer(&buf[0])), C.DWORD(size), &bytesRead, C.ULONG64(offset))
+
+	switch uint32(status) {
+	case VfsStatusSuccess:
+		return buf[:bytesRead], nil
+	case VfsStatusEndOfFile:
+		return buf[:bytesRead], io.EOF
+	default:
+		return nil, fmt.Errorf("VMMDLL_VfsReadU: failed to read %s: status 0x%x", path, uint32(status))
+	}
+}
+
+// VfsRead reads up to size bytes from the file at path starting at offset. Reading at
+// or past the end of the file returns io.EOF.
+func (vmm *Vmm) VfsRead(ctx context.Context, path string, offset uint64, size uint32) ([]byte, error) {
+	resultChan := make(chan struct {
+		data []byte
+		err  error
+	}, 1)
+
+	go func() {
+		data, err := vmm.vfsRead(path, offset, size)
+		resultChan <- struct {
+			data []byte
+			err  error
+		}{data, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.data, result.err
+	}
+}
+
+func (vmm *Vmm) vfsWrite(path string, data []byte, offset uint64) (uint32, error) {
+	uszPath, err := vfsPath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) == 0 {
+		return 0, nil
+	}
+
+	cPath := C.CString(uszPath)
+	defer C.free(unsafe.Pointer(cPath))
+
+	var bytesWritten C.DWORD
+	status := C.VMMDLL_VfsWriteU(C.VMM_HANDLE(vmm.handle), cPath, (*C.BYTE)(unsafe.Pointer(&data[0])), C.DWORD(len(data)), &bytesWritten, C.ULONG64(offset))
+
+	if uint32(status) != VfsStatusSuccess {
+		return uint32(bytesWritten), fmt.Errorf("VMMDLL_VfsWriteU: failed to write %s: status 0x%x", path, uint32(status))
+	}
+
+	return uint32(bytesWritten), nil
+}
+
+// VfsWrite writes data to the file at path starting at offset and returns the number
+// of bytes written.
+func (vmm *Vmm) VfsWrite(ctx context.Context, path string, data []byte, offset uint64) (uint32, error) {
+	resultChan := make(chan struct {
+		written uint32
+		err     error
+	}, 1)
+
+	go func() {
+		written, err := vmm.vfsWrite(path, data, offset)
+		resultChan <- struct {
+			written uint32
+			err     error
+		}{written, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case result := <-resultChan:
+		return result.written, result.err
+	}
+}
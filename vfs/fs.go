@@ -0,0 +1,390 @@
+// Package vfs exposes the MemProcFS virtual file system as an io/fs file system.
+//
+// The NTFS reconstruction of forensic mode is opened with OpenNTFS(ctx, vmm) rather
+// than a Vmm method, since this package imports the root package.
+package vfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+)
+
+// maxReadChunk bounds the size of a single VfsRead call.
+const maxReadChunk = 1 << 20
+
+// Backend is the part of *memprocfs.Vmm used by FS. VfsList of a directory that
+// doesn't exist returns an error wrapping fs.ErrNotExist.
+type Backend interface {
+	VfsList(ctx context.Context, path string) ([]memprocfs.VfsEntry, error)
+	VfsRead(ctx context.Context, path string, offset uint64, size uint32) ([]byte, error)
+	VfsWrite(ctx context.Context, path string, data []byte, offset uint64) (uint32, error)
+}
+
+// FS exposes the MemProcFS virtual file system as an io/fs file system.
+type FS struct {
+	backend Backend
+	ctx     context.Context
+	root    string
+
+	// sys, if set, supplies the value returned by FileInfo.Sys for name.
+	sys func(name string, entry memprocfs.VfsEntry) any
+}
+
+var (
+	_ fs.FS         = (*FS)(nil)
+	_ fs.ReadDirFS  = (*FS)(nil)
+	_ fs.StatFS     = (*FS)(nil)
+	_ fs.ReadFileFS = (*FS)(nil)
+	_ fs.SubFS      = (*FS)(nil)
+)
+
+func New(backend Backend) *FS {
+	return &FS{
+		backend: backend,
+		ctx:     context.Background(),
+		root:    "/",
+	}
+}
+
+// WithContext returns a copy of the file system whose VFS calls are bound to ctx.
+func (f *FS) WithContext(ctx context.Context) *FS {
+	return &FS{
+		backend: f.backend,
+		ctx:     ctx,
+		root:    f.root,
+		sys:     f.sys,
+	}
+}
+
+// Backend returns the backend the file system reads from.
+func (f *FS) Backend() Backend {
+	return f.backend
+}
+
+// VfsPath returns the absolute VFS path of name, e.g. "sys/version.txt" -> "/sys/version.txt".
+func (f *FS) VfsPath(name string) string {
+	if name == "." {
+		return f.root
+	}
+	return path.Join(f.root, name)
+}
+
+func (f *FS) Sub(dir string) (fs.FS, error) {
+	if !fs.ValidPath(dir) {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: fs.ErrInvalid}
+	}
+	info, err := f.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, &fs.PathError{Op: "sub", Path: dir, Err: errors.New("not a directory")}
+	}
+
+	sub := &FS{backend: f.backend, ctx: f.ctx, root: f.VfsPath(dir)}
+	if f.sys != nil {
+		// names passed to sys are relative to the root of f
+		sys := f.sys
+		sub.sys = func(name string, entry memprocfs.VfsEntry) any {
+			return sys(path.Join(dir, name), entry)
+		}
+	}
+	return sub, nil
+}
+
+func (f *FS) Open(name string) (fs.File, error) {
+	info, err := f.stat("open", name)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return &dir{fs: f, name: name, info: info}, nil
+	}
+
+	return &File{fs: f, name: name, info: info}, nil
+}
+
+func (f *FS) Stat(name string) (fs.FileInfo, error) {
+	return f.stat("stat", name)
+}
+
+func (f *FS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+	return f.readDir(name)
+}
+
+func (f *FS) ReadFile(name string) ([]byte, error) {
+	info, err := f.stat("readfile", name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: errors.New("is a directory")}
+	}
+
+	file := &File{fs: f, name: name, info: info}
+	return io.ReadAll(io.NewSectionReader(file, 0, info.Size()))
+}
+
+func (f *FS) readDir(name string) ([]fs.DirEntry, error) {
+	entries, err := f.backend.VfsList(f.ctx, f.VfsPath(name))
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	result := make([]fs.DirEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = f.newFileInfo(path.Join(name, entry.Name), entry)
+	}
+
+	// fs.ReadDirFS requires the entries sorted by filename
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name() < result[j].Name()
+	})
+
+	return result, nil
+}
+
+// stat resolves name by listing its parent directory since the VFS has no stat call.
+func (f *FS) stat(op string, name string) (*fileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return f.newFileInfo(name, memprocfs.VfsEntry{Name: path.Base(f.root), IsDir: true}), nil
+	}
+
+	parent, base := path.Split(name)
+	if parent == "" {
+		parent = "."
+	} else {
+		parent = strings.TrimSuffix(parent, "/")
+	}
+
+	entries, err := f.backend.VfsList(f.ctx, f.VfsPath(parent))
+	if err != nil {
+		return nil, &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	var folded *memprocfs.VfsEntry
+	for i := range entries {
+		if entries[i].Name == base {
+			return f.newFileInfo(name, entries[i]), nil
+		}
+		if folded == nil && strings.EqualFold(entries[i].Name, base) {
+			folded = &entries[i]
+		}
+	}
+
+	if folded != nil {
+		return f.newFileInfo(path.Join(parent, folded.Name), *folded), nil
+	}
+
+	return nil, &fs.PathError{Op: op, Path: name, Err: fs.ErrNotExist}
+}
+
+func (f *FS) newFileInfo(name string, entry memprocfs.VfsEntry) *fileInfo {
+	info := &fileInfo{entry: entry}
+	if f.sys != nil {
+		info.sys = f.sys(name, entry)
+	}
+	return info
+}
+
+// fileInfo implements both fs.FileInfo and fs.DirEntry.
+type fileInfo struct {
+	entry memprocfs.VfsEntry
+	sys   any
+}
+
+func (i *fileInfo) Name() string {
+	return i.entry.Name
+}
+
+func (i *fileInfo) Size() int64 {
+	return int64(i.entry.Size)
+}
+
+func (i *fileInfo) Mode() fs.FileMode {
+	if i.entry.IsDir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i *fileInfo) ModTime() time.Time {
+	return i.entry.LastWriteTime
+}
+
+func (i *fileInfo) IsDir() bool {
+	return i.entry.IsDir
+}
+
+// Sys returns the underlying memprocfs.VfsEntry, or *MFTEntry for files of an NTFS file system.
+func (i *fileInfo) Sys() any {
+	if i.sys != nil {
+		return i.sys
+	}
+	return i.entry
+}
+
+func (i *fileInfo) Type() fs.FileMode {
+	return i.Mode().Type()
+}
+
+func (i *fileInfo) Info() (fs.FileInfo, error) {
+	return i, nil
+}
+
+type dir struct {
+	fs      *FS
+	name    string
+	info    *fileInfo
+	entries []fs.DirEntry
+	read    bool
+	offset  int
+}
+
+func (d *dir) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.name, Err: errors.New("is a directory")}
+}
+
+func (d *dir) Close() error {
+	return nil
+}
+
+func (d *dir) ReadDir(n int) ([]fs.DirEntry, error) {
+	if !d.read {
+		entries, err := d.fs.readDir(d.name)
+		if err != nil {
+			return nil, err
+		}
+		d.entries = entries
+		d.read = true
+	}
+
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// File is a regular VFS file. It implements io.ReaderAt, io.Seeker and io.WriterAt on
+// top of the VfsRead and VfsWrite calls.
+type File struct {
+	fs     *FS
+	name   string
+	info   *fileInfo
+	offset int64
+}
+
+var (
+	_ io.ReaderAt = (*File)(nil)
+	_ io.Seeker   = (*File)(nil)
+	_ io.WriterAt = (*File)(nil)
+)
+
+func (f *File) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (f *File) Close() error {
+	return nil
+}
+
+func (f *File) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	total := 0
+	for total < len(p) {
+		chunk := len(p) - total
+		if chunk > maxReadChunk {
+			chunk = maxReadChunk
+		}
+
+		data, err := f.fs.backend.VfsRead(f.fs.ctx, f.fs.VfsPath(f.name), uint64(off)+uint64(total), uint32(chunk))
+		total += copy(p[total:], data)
+
+		if err != nil && err != io.EOF {
+			return total, &fs.PathError{Op: "read", Path: f.name, Err: err}
+		}
+		if err == io.EOF || len(data) < chunk {
+			break
+		}
+	}
+
+	if total < len(p) {
+		return total, io.EOF
+	}
+	return total, nil
+}
+
+func (f *File) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += f.info.Size()
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if offset < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *File) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	written, err := f.fs.backend.VfsWrite(f.fs.ctx, f.fs.VfsPath(f.name), p, uint64(off))
+	if err != nil {
+		return int(written), &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+	if int(written) < len(p) {
+		return int(written), io.ErrShortWrite
+	}
+	return int(written), nil
+}
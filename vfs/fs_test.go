@@ -0,0 +1,146 @@
+package vfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+)
+
+var testModTime = time.Date(2023, 1, 15, 12, 34, 56, 0, time.UTC)
+
+// memBackend is an in-memory VFS holding files by absolute path. Directories are
+// implied by the file paths.
+type memBackend struct {
+	files map[string][]byte
+	// err, if set, fails every call
+	err error
+}
+
+func (b *memBackend) VfsList(ctx context.Context, dir string) ([]memprocfs.VfsEntry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	seen := make(map[string]bool)
+	var entries []memprocfs.VfsEntry
+
+	for name, data := range b.files {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		base, _, isDir := strings.Cut(strings.TrimPrefix(name, prefix), "/")
+		if seen[base] {
+			continue
+		}
+		seen[base] = true
+
+		entry := memprocfs.VfsEntry{Name: base, IsDir: isDir, LastWriteTime: testModTime}
+		if !isDir {
+			entry.Size = uint64(len(data))
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 && dir != "/" {
+		return nil, fs.ErrNotExist
+	}
+	return entries, nil
+}
+
+func (b *memBackend) VfsRead(ctx context.Context, name string, offset uint64, size uint32) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	data, ok := b.files[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	if offset >= uint64(len(data)) {
+		return nil, io.EOF
+	}
+	end := offset + uint64(size)
+	if end > uint64(len(data)) {
+		end = uint64(len(data))
+	}
+	return append([]byte(nil), data[offset:end]...), nil
+}
+
+func (b *memBackend) VfsWrite(ctx context.Context, name string, data []byte, offset uint64) (uint32, error) {
+	return 0, ErrReadOnly
+}
+
+func newTestBackend() *memBackend {
+	return &memBackend{files: map[string][]byte{
+		"/sys/version.txt":          []byte("5.8.0"),
+		"/sys/computername.txt":     []byte("DESKTOP"),
+		"/sys/config/flag.txt":      []byte("0"),
+		"/name/System-4/dtb.txt":    []byte("1aa000"),
+		"/forensic/progress.txt":    []byte("100"),
+		"/forensic/csv/process.csv": []byte("PID,PPID\n4,0\n"),
+	}}
+}
+
+func TestFS(t *testing.T) {
+	fsys := New(newTestBackend())
+
+	err := fstest.TestFS(fsys, "sys/version.txt", "sys/config/flag.txt", "name/System-4/dtb.txt", "forensic/csv/process.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSSub(t *testing.T) {
+	sub, err := fs.Sub(New(newTestBackend()), "sys")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(sub, "version.txt", "computername.txt", "config/flag.txt"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFSStatErrors(t *testing.T) {
+	backendErr := errors.New("device read failed")
+
+	tests := []struct {
+		name    string
+		fsys    *FS
+		path    string
+		wantErr error
+	}{
+		{name: "missing file", fsys: New(newTestBackend()), path: "sys/missing.txt", wantErr: fs.ErrNotExist},
+		{name: "missing directory", fsys: New(newTestBackend()), path: "missing/file.txt", wantErr: fs.ErrNotExist},
+		{name: "backend failure", fsys: New(&memBackend{err: backendErr}), path: "sys/version.txt", wantErr: backendErr},
+		{name: "canceled", fsys: New(newTestBackend()).WithContext(canceledContext()), path: "sys/version.txt", wantErr: context.Canceled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.fsys.Stat(tt.path)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Stat error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != fs.ErrNotExist && errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("Stat error = %v, reported as not existing", err)
+			}
+		})
+	}
+}
+
+func canceledContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	return ctx
+}
@@ -0,0 +1,235 @@
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+	"time"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+)
+
+const (
+	// NTFSDir is the NTFS reconstruction relative to the VFS root.
+	NTFSDir = "forensic/ntfs"
+
+	// NTFSFilesCSV lists the recovered MFT entries.
+	NTFSFilesCSV = "forensic/csv/ntfs_files.csv"
+)
+
+var ErrNotResident = errors.New("file data is not resident in the MFT record")
+
+// MFTEntry is the metadata of a file recovered from the MFT.
+type MFTEntry struct {
+	RecordNumber       uint32
+	ParentRecordNumber uint32
+	Path               string // relative to NTFSDir, slash separated
+	Size               uint64
+	Resident           bool
+	Deleted            bool
+	CreationTime       time.Time
+	ModifyTime         time.Time
+	AccessTime         time.Time
+}
+
+// NTFS is a file system over the NTFS reconstruction in /forensic/ntfs/, available
+// once forensic mode has completed. FileInfo.Sys returns the *MFTEntry of a file if
+// its metadata is known, otherwise the memprocfs.VfsEntry.
+type NTFS struct {
+	fs  *FS
+	mft map[string]*MFTEntry
+}
+
+var (
+	_ fs.ReadDirFS  = (*NTFS)(nil)
+	_ fs.StatFS     = (*NTFS)(nil)
+	_ fs.ReadFileFS = (*NTFS)(nil)
+)
+
+// ntfsColumns maps the ntfs_files.csv header to MFTEntry fields. Other columns are
+// ignored.
+var ntfsColumns = map[string]string{
+	"RecordNumber":       "record",
+	"ParentRecordNumber": "parent",
+	"Size":               "size",
+	"Deleted":            "deleted",
+	"Resident":           "resident",
+	"CreateTime":         "created",
+	"ModifyTime":         "modified",
+	"ReadTime":           "accessed",
+	"Path":               "path",
+}
+
+var ntfsTimeLayouts = []string{
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+}
+
+// NTFS opens the NTFS reconstruction. A missing MFT listing is not an error, the
+// files are still accessible but without MFT metadata.
+func (f *FS) NTFS() (*NTFS, error) {
+	ntfs := &NTFS{mft: make(map[string]*MFTEntry)}
+
+	if data, err := f.ReadFile(NTFSFilesCSV); err == nil {
+		if err := ntfs.parseFiles(data); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &FS{backend: f.backend, ctx: f.ctx, root: f.VfsPath(NTFSDir)}
+	if _, err := sub.ReadDir("."); err != nil {
+		return nil, err
+	}
+	sub.sys = func(name string, entry memprocfs.VfsEntry) any {
+		if mft, ok := ntfs.mft[ntfsKey(name)]; ok {
+			return mft
+		}
+		return entry
+	}
+	ntfs.fs = sub
+
+	return ntfs, nil
+}
+
+// OpenNTFS opens the NTFS reconstruction of a Vmm, e.g. vfs.OpenNTFS(ctx, vmm).
+func OpenNTFS(ctx context.Context, backend Backend) (*NTFS, error) {
+	return New(backend).WithContext(ctx).NTFS()
+}
+
+func ntfsKey(name string) string {
+	return strings.ToLower(strings.Trim(strings.ReplaceAll(name, "\\", "/"), "/"))
+}
+
+func parseNTFSTime(value string) time.Time {
+	for _, layout := range ntfsTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC()
+		}
+	}
+	return time.Time{}
+}
+
+func parseNTFSBool(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "y":
+		return true
+	}
+	return false
+}
+
+func parseNTFSUint(value string) uint64 {
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+func (n *NTFS) parseFiles(data []byte) error {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, len(header))
+	hasPath := false
+	for i, name := range header {
+		columns[i] = ntfsColumns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))]
+		hasPath = hasPath || columns[i] == "path"
+	}
+	if !hasPath {
+		return fmt.Errorf("%s: missing column %q", NTFSFilesCSV, "Path")
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entry := &MFTEntry{}
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			value = strings.TrimSpace(value)
+
+			switch columns[i] {
+			case "record":
+				entry.RecordNumber = uint32(parseNTFSUint(value))
+			case "parent":
+				entry.ParentRecordNumber = uint32(parseNTFSUint(value))
+			case "path":
+				entry.Path = strings.Trim(strings.ReplaceAll(value, "\\", "/"), "/")
+			case "size":
+				entry.Size = parseNTFSUint(value)
+			case "resident":
+				entry.Resident = parseNTFSBool(value)
+			case "deleted":
+				entry.Deleted = parseNTFSBool(value)
+			case "created":
+				entry.CreationTime = parseNTFSTime(value)
+			case "modified":
+				entry.ModifyTime = parseNTFSTime(value)
+			case "accessed":
+				entry.AccessTime = parseNTFSTime(value)
+			}
+		}
+
+		if entry.Path != "" {
+			n.mft[ntfsKey(entry.Path)] = entry
+		}
+	}
+}
+
+func (n *NTFS) Open(name string) (fs.File, error) {
+	return n.fs.Open(name)
+}
+
+func (n *NTFS) Stat(name string) (fs.FileInfo, error) {
+	return n.fs.Stat(name)
+}
+
+func (n *NTFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	return n.fs.ReadDir(name)
+}
+
+func (n *NTFS) ReadFile(name string) ([]byte, error) {
+	return n.fs.ReadFile(name)
+}
+
+// MFT returns the MFT metadata of name.
+func (n *NTFS) MFT(name string) (*MFTEntry, bool) {
+	entry, ok := n.mft[ntfsKey(name)]
+	return entry, ok
+}
+
+// Recover copies the data of a file resident in its MFT record to w. Non-resident
+// file data isn't held in the MFT and fails with ErrNotResident.
+func (n *NTFS) Recover(name string, w io.Writer) (int64, error) {
+	entry, ok := n.MFT(name)
+	if !ok || !entry.Resident {
+		return 0, &fs.PathError{Op: "recover", Path: name, Err: ErrNotResident}
+	}
+
+	file, err := n.fs.Open(name)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	return io.Copy(w, io.LimitReader(file, int64(entry.Size)))
+}
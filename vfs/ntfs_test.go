@@ -0,0 +1,136 @@
+package vfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+const testNTFSFiles = `RecordNumber,ParentRecordNumber,PhysicalAddress,Size,Deleted,Resident,CreateTime,ModifyTime,ReadTime,Name,Path,Future
+42,5,0x1a2b000,010,0,1,2023-01-15 12:34:56 UTC,2023-01-15 12:35:00 UTC,,hosts,\Windows\System32\drivers\etc\hosts,x
+43,5,0x1a2b400,1048576,1,0,2023-01-15 12:34:56,,,big.bin,\Users\Public\big.bin,
+44,5,0x1a2b800,5,0,1,,,,,,
+`
+
+func newNTFSBackend(csv string) *memBackend {
+	backend := &memBackend{files: map[string][]byte{
+		"/forensic/ntfs/Windows/System32/drivers/etc/hosts": []byte("127.0.0.1 localhost\n"),
+		"/forensic/ntfs/Users/Public/big.bin":               make([]byte, 16),
+		"/forensic/ntfs/Users/Public/unknown.txt":           []byte("?"),
+	}}
+	if csv != "" {
+		backend.files["/"+NTFSFilesCSV] = []byte(csv)
+	}
+	return backend
+}
+
+func TestNTFSParseFiles(t *testing.T) {
+	n := &NTFS{mft: make(map[string]*MFTEntry)}
+	if err := n.parseFiles([]byte(testNTFSFiles)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(n.mft) != 2 {
+		t.Errorf("parsed %d entries, want 2 (rows without a path are skipped)", len(n.mft))
+	}
+
+	hosts, ok := n.MFT("windows/system32/DRIVERS/etc/hosts")
+	if !ok {
+		t.Fatal("MFT entry of hosts not found")
+	}
+	want := MFTEntry{
+		RecordNumber:       42,
+		ParentRecordNumber: 5,
+		Path:               "Windows/System32/drivers/etc/hosts",
+		Size:               10, // leading zero, still decimal
+		Resident:           true,
+		CreationTime:       time.Date(2023, 1, 15, 12, 34, 56, 0, time.UTC),
+		ModifyTime:         time.Date(2023, 1, 15, 12, 35, 0, 0, time.UTC),
+	}
+	if *hosts != want {
+		t.Errorf("hosts = %+v, want %+v", *hosts, want)
+	}
+
+	big, ok := n.MFT("/Users/Public/big.bin")
+	if !ok {
+		t.Fatal("MFT entry of big.bin not found")
+	}
+	if big.Resident || !big.Deleted || big.Size != 1048576 {
+		t.Errorf("big.bin = %+v", *big)
+	}
+	if _, ok := n.MFT("Users/Public/unknown.txt"); ok {
+		t.Error("MFT entry found for a file not in the listing")
+	}
+}
+
+func TestNTFSParseFilesMissingPath(t *testing.T) {
+	n := &NTFS{mft: make(map[string]*MFTEntry)}
+	if err := n.parseFiles([]byte("RecordNumber,Size\n42,10\n")); err == nil {
+		t.Error("parseFiles without a Path column succeeded")
+	}
+}
+
+func TestOpenNTFS(t *testing.T) {
+	ntfs, err := OpenNTFS(context.Background(), newNTFSBackend(testNTFSFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := ntfs.Stat("Windows/System32/drivers/etc/hosts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, ok := info.Sys().(*MFTEntry); !ok || entry.RecordNumber != 42 {
+		t.Errorf("Sys() = %#v, want the MFT entry of record 42", info.Sys())
+	}
+
+	info, err = ntfs.Stat("Users/Public/unknown.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := info.Sys().(*MFTEntry); ok {
+		t.Error("Sys() of a file without MFT metadata is an MFTEntry")
+	}
+}
+
+func TestOpenNTFSWithoutListing(t *testing.T) {
+	ntfs, err := OpenNTFS(context.Background(), newNTFSBackend(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ntfs.ReadFile("Windows/System32/drivers/etc/hosts"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestOpenNTFSMissing(t *testing.T) {
+	_, err := OpenNTFS(context.Background(), newTestBackend())
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("OpenNTFS error = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestNTFSRecover(t *testing.T) {
+	ntfs, err := OpenNTFS(context.Background(), newNTFSBackend(testNTFSFiles))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	n, err := ntfs.Recover("Windows/System32/drivers/etc/hosts", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 || buf.String() != "127.0.0.1 " {
+		t.Errorf("Recover = %d, %q, want the 10 bytes of the MFT size", n, buf.String())
+	}
+
+	for _, name := range []string{"Users/Public/big.bin", "Users/Public/unknown.txt"} {
+		if _, err := ntfs.Recover(name, &buf); !errors.Is(err, ErrNotResident) {
+			t.Errorf("Recover(%s) error = %v, want ErrNotResident", name, err)
+		}
+	}
+}
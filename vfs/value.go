@@ -0,0 +1,236 @@
+package vfs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	memprocfs "github.com/sergeyzav/memprocfs"
+)
+
+var ErrReadOnly = errors.New("file is read-only")
+
+// ReadFromBytes reads from a file backed by data, like VMMDLL_UtilVfsReadFile_FromPBYTE.
+// It returns io.EOF when offset is at or beyond the end of data.
+func ReadFromBytes(data []byte, p []byte, offset uint64) (int, error) {
+	if offset >= uint64(len(data)) {
+		return 0, io.EOF
+	}
+	return copy(p, data[offset:]), nil
+}
+
+// ReadFromQWORD reads from a file containing value as 16 hex digits, like
+// VMMDLL_UtilVfsReadFile_FromQWORD. prefix prepends "0x".
+func ReadFromQWORD(value uint64, p []byte, offset uint64, prefix bool) (int, error) {
+	return ReadFromBytes(formatHex(value, 16, prefix), p, offset)
+}
+
+// ReadFromDWORD reads from a file containing value as 8 hex digits, like
+// VMMDLL_UtilVfsReadFile_FromDWORD. prefix prepends "0x".
+func ReadFromDWORD(value uint32, p []byte, offset uint64, prefix bool) (int, error) {
+	return ReadFromBytes(formatHex(uint64(value), 8, prefix), p, offset)
+}
+
+// ReadFromBool reads from a file containing "1" or "0", like VMMDLL_UtilVfsReadFile_FromBOOL.
+func ReadFromBool(value bool, p []byte, offset uint64) (int, error) {
+	return ReadFromBytes(formatBool(value), p, offset)
+}
+
+// WriteBool updates target from a write to a bool file, like VMMDLL_UtilVfsWriteFile_BOOL.
+// Only a write at offset 0 changes the value; "0" or NUL is false, anything else true.
+// The whole write is always reported as consumed.
+func WriteBool(target *bool, p []byte, offset uint64) (int, error) {
+	if offset == 0 && len(p) > 0 {
+		*target = p[0] != 0 && p[0] != '0'
+	}
+	return len(p), nil
+}
+
+// WriteDWORD updates target from a write to a hex DWORD file, like VMMDLL_UtilVfsWriteFile_DWORD.
+// The written bytes overlay the current 8 digit text at offset and the result is
+// parsed as hex up to the first non hex character. Values below minAllow are raised
+// to minAllow.
+func WriteDWORD(target *uint32, p []byte, offset uint64, minAllow uint32) (int, error) {
+	if offset < 8 {
+		value := uint32(overlayHex(uint64(*target), 8, p, offset))
+		if value < minAllow {
+			value = minAllow
+		}
+		*target = value
+	}
+	return len(p), nil
+}
+
+// WriteQWORD is the QWORD counterpart of WriteDWORD.
+func WriteQWORD(target *uint64, p []byte, offset uint64, minAllow uint64) (int, error) {
+	if offset < 16 {
+		value := overlayHex(*target, 16, p, offset)
+		if value < minAllow {
+			value = minAllow
+		}
+		*target = value
+	}
+	return len(p), nil
+}
+
+func formatHex(value uint64, digits int, prefix bool) []byte {
+	if prefix {
+		return []byte(fmt.Sprintf("0x%0*x", digits, value))
+	}
+	return []byte(fmt.Sprintf("%0*x", digits, value))
+}
+
+func formatBool(value bool) []byte {
+	if value {
+		return []byte{'1'}
+	}
+	return []byte{'0'}
+}
+
+// overlayHex writes p over the hex text of value at offset and parses the result
+// the way strtoul does: leading hex digits only.
+func overlayHex(value uint64, digits int, p []byte, offset uint64) uint64 {
+	text := formatHex(value, digits, false)
+	copy(text[offset:], p)
+
+	var result uint64
+	for _, c := range text {
+		switch {
+		case c >= '0' && c <= '9':
+			result = result<<4 | uint64(c-'0')
+		case c >= 'a' && c <= 'f':
+			result = result<<4 | uint64(c-'a'+10)
+		case c >= 'A' && c <= 'F':
+			result = result<<4 | uint64(c-'A'+10)
+		default:
+			return result
+		}
+	}
+	return result
+}
+
+// Value is the set of types a ValueFile can be bound to.
+type Value interface {
+	bool | uint32 | uint64
+}
+
+// ValueFile binds a Go variable to a virtual file, e.g. for a Plugin. Booleans are
+// exposed as "1"/"0", integers as fixed width hex.
+//
+// The bound variable must only be accessed through Get and Set once the file is in use.
+type ValueFile[T Value] struct {
+	mu    sync.RWMutex
+	value *T
+
+	// Prefix prepends "0x" to integer values.
+	Prefix bool
+
+	// Writable allows writes to change the value.
+	Writable bool
+
+	// Min is the lowest value accepted by a write to an integer file.
+	Min T
+}
+
+func NewValueFile[T Value](value *T) *ValueFile[T] {
+	return &ValueFile[T]{value: value}
+}
+
+func (f *ValueFile[T]) Get() T {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return *f.value
+}
+
+func (f *ValueFile[T]) Set(value T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	*f.value = value
+}
+
+func (f *ValueFile[T]) bytes() []byte {
+	switch value := any(f.value).(type) {
+	case *bool:
+		return formatBool(*value)
+	case *uint32:
+		return formatHex(uint64(*value), 8, f.Prefix)
+	case *uint64:
+		return formatHex(*value, 16, f.Prefix)
+	}
+	return nil
+}
+
+// Size returns the size of the file contents.
+func (f *ValueFile[T]) Size() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return uint64(len(f.bytes()))
+}
+
+// Entry returns the directory entry of the file, for use in Plugin.List.
+func (f *ValueFile[T]) Entry(name string) memprocfs.VfsEntry {
+	return memprocfs.VfsEntry{
+		Name:          name,
+		Size:          f.Size(),
+		LastWriteTime: time.Now(),
+	}
+}
+
+func (f *ValueFile[T]) Read(p []byte, offset uint64) (int, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return ReadFromBytes(f.bytes(), p, offset)
+}
+
+// Write updates the value with the semantics of WriteBool and WriteDWORD. If Prefix
+// is enabled offsets include the "0x" prefix, except that a write at offset 0 may
+// omit it, e.g. "echo 12 > file".
+func (f *ValueFile[T]) Write(p []byte, offset uint64) (int, error) {
+	if !f.Writable {
+		return 0, ErrReadOnly
+	}
+
+	n := len(p)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	prefixLen := uint64(0)
+	if f.Prefix {
+		prefixLen = 2
+	}
+
+	switch value := any(f.value).(type) {
+	case *bool:
+		return WriteBool(value, p, offset)
+	case *uint32:
+		p, offset = stripPrefix(p, offset, prefixLen)
+		_, err := WriteDWORD(value, p, offset, any(f.Min).(uint32))
+		return n, err
+	case *uint64:
+		p, offset = stripPrefix(p, offset, prefixLen)
+		_, err := WriteQWORD(value, p, offset, any(f.Min).(uint64))
+		return n, err
+	}
+	return n, nil
+}
+
+// stripPrefix drops the part of a write that falls into the "0x" prefix.
+func stripPrefix(p []byte, offset uint64, prefixLen uint64) ([]byte, uint64) {
+	if offset == 0 {
+		if len(p) >= 2 && p[0] == '0' && (p[1] == 'x' || p[1] == 'X') {
+			return p[2:], 0
+		}
+		return p, 0
+	}
+	if offset >= prefixLen {
+		return p, offset - prefixLen
+	}
+	skip := prefixLen - offset
+	if skip > uint64(len(p)) {
+		skip = uint64(len(p))
+	}
+	return p[skip:], 0
+}
@@ -0,0 +1,90 @@
+package vfs
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOverlayHex(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  uint64
+		digits int
+		p      string
+		offset uint64
+		want   uint64
+	}{
+		{name: "full write", value: 0x10, digits: 8, p: "0000abcd", want: 0xabcd},
+		{name: "upper case", value: 0, digits: 8, p: "0000ABCD", want: 0xabcd},
+		{name: "overlay at offset", value: 0x12345678, digits: 8, p: "ff", offset: 6, want: 0x123456ff},
+		{name: "short write stops at newline", value: 0x12345678, digits: 8, p: "12\n", want: 0x12},
+		{name: "short write keeps tail", value: 0x12345678, digits: 8, p: "ab", want: 0xab345678},
+		{name: "non hex first", value: 0x12345678, digits: 8, p: "x", want: 0},
+		{name: "longer than digits", value: 0, digits: 8, p: "123456789", want: 0x12345678},
+		{name: "qword", value: 0, digits: 16, p: "ffffffffffffffff", want: ^uint64(0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overlayHex(tt.value, tt.digits, []byte(tt.p), tt.offset); got != tt.want {
+				t.Errorf("overlayHex = %#x, want %#x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		p          string
+		offset     uint64
+		prefixLen  uint64
+		want       string
+		wantOffset uint64
+	}{
+		{name: "offset 0 with prefix", p: "0x12", prefixLen: 2, want: "12"},
+		{name: "offset 0 upper case prefix", p: "0X12", prefixLen: 2, want: "12"},
+		{name: "offset 0 without prefix", p: "12", prefixLen: 2, want: "12"},
+		{name: "offset 0 no prefix configured", p: "0x12", prefixLen: 0, want: "12"},
+		{name: "inside prefix", p: "x12", offset: 1, prefixLen: 2, want: "12"},
+		{name: "entirely inside prefix", p: "x", offset: 1, prefixLen: 2, want: ""},
+		{name: "after prefix", p: "ff", offset: 8, prefixLen: 2, want: "ff", wantOffset: 6},
+		{name: "no prefix configured", p: "ff", offset: 8, prefixLen: 0, want: "ff", wantOffset: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, offset := stripPrefix([]byte(tt.p), tt.offset, tt.prefixLen)
+			if !bytes.Equal(got, []byte(tt.want)) || offset != tt.wantOffset {
+				t.Errorf("stripPrefix = %q, %d, want %q, %d", got, offset, tt.want, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestValueFileWrite(t *testing.T) {
+	value := uint32(0x10)
+	file := NewValueFile(&value)
+	file.Prefix = true
+	file.Writable = true
+	file.Min = 4
+
+	if _, err := file.Write([]byte("0x00000020"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := file.Get(); got != 0x20 {
+		t.Errorf("after prefixed write = %#x, want 0x20", got)
+	}
+
+	if _, err := file.Write([]byte("1\n"), 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := file.Get(); got != 4 {
+		t.Errorf("after write below Min = %#x, want 4", got)
+	}
+
+	file.Writable = false
+	if _, err := file.Write([]byte("1"), 0); err != ErrReadOnly {
+		t.Errorf("read-only write error = %v, want ErrReadOnly", err)
+	}
+}
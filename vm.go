@@ -0,0 +1,170 @@
+package go_memprocfs
+
+/*
+#include "vmmdll.h"
+*/
+import "C"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+type VMType uint32
+
+const (
+	VMTypeUnknown VMType = iota
+	VMTypeHyperV
+	VMTypeHyperVWHVP
+)
+
+func (v VMType) String() string {
+	switch v {
+	case VMTypeUnknown:
+		return "Unknown"
+	case VMTypeHyperV:
+		return "HyperV"
+	case VMTypeHyperVWHVP:
+		return "HyperV-WHVP"
+	default:
+		return "Unknown"
+	}
+}
+
+var ErrVMNotActive = errors.New("virtual machine is not active")
+
+type VMEntry struct {
+	Handle           uint64
+	Name             string
+	GpaMax           uint64
+	Type             VMType
+	Active           bool
+	ReadOnly         bool
+	PhysicalOnly     bool
+	PartitionID      uint32
+	VersionBuild     uint32
+	System           SystemType
+	ParentVmmMountID uint32
+	VmMemPID         uint32
+}
+
+type VM struct {
+	Version   uint32
+	MultiText []string
+	Entries   []VMEntry
+}
+
+func newVMEntry(cEntry *C.VMMDLL_MAP_VMENTRY) VMEntry {
+	return VMEntry{
+		Handle:           uint64(uintptr(unsafe.Pointer(cEntry.hVM))),
+		Name:             readCString(afterField(unsafe.Pointer(cEntry), unsafe.Offsetof(cEntry.hVM), unsafe.Sizeof(cEntry.hVM))),
+		GpaMax:           uint64(cEntry.gpaMax),
+		Type:             VMType(cEntry.tp),
+		Active:           cEntry.fActive != 0,
+		ReadOnly:         cEntry.fReadOnly != 0,
+		PhysicalOnly:     cEntry.fPhysicalOnly != 0,
+		PartitionID:      uint32(cEntry.dwPartitionID),
+		VersionBuild:     uint32(cEntry.dwVersionBuild),
+		System:           SystemType(cEntry.tpSystem),
+		ParentVmmMountID: uint32(cEntry.dwParentVmmMountID),
+		VmMemPID:         uint32(cEntry.dwVmMemPID),
+	}
+}
+
+func newVM(cVM *C.VMMDLL_MAP_VM) VM {
+	vm := VM{
+		Version: uint32(cVM.dwVersion),
+	}
+
+	if cVM.pbMultiText != nil && cVM.cbMultiText > 0 {
+		vm.MultiText = multiString(C.GoBytes(unsafe.Pointer(cVM.pbMultiText), C.int(cVM.cbMultiText)))
+	}
+
+	count := int(cVM.cMap)
+	vm.Entries = make([]VMEntry, count)
+	for i, cEntry := range cArray[C.VMMDLL_MAP_VMENTRY](afterDWORD(unsafe.Pointer(&cVM.cMap)), count) {
+		vm.Entries[i] = newVMEntry(cEntry)
+	}
+
+	return vm
+}
+
+func (vmm *Vmm) getVMMap() (*VM, error) {
+	var cVMMap C.PVMMDLL_MAP_VM
+	success := C.VMMDLL_Map_GetVMU(C.VMM_HANDLE(vmm.handle), &cVMMap)
+
+	if success == 0 || cVMMap == nil {
+		return nil, fmt.Errorf("failed to get VM map")
+	}
+
+	defer freeMemory(C.PVOID(cVMMap))
+
+	if cVMMap.dwVersion != MapVMVersion {
+		return nil, ErrUnsupportedVMVersion
+	}
+
+	vm := newVM(cVMMap)
+
+	return &vm, nil
+}
+
+// GetVMMap lists the child virtual machines detected in the target. VM detection must
+// be enabled with the -vm (or -vm-basic / -vm-nested) startup option.
+func (vmm *Vmm) GetVMMap(ctx context.Context) (*VM, error) {
+	resultChan := make(chan struct {
+		vm  *VM
+		err error
+	}, 1)
+
+	go func() {
+		vm, err := vmm.getVMMap()
+		resultChan <- struct {
+			vm  *VM
+			err error
+		}{vm, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.vm, result.err
+	}
+}
+
+// OpenChildVM initializes a new Vmm over the guest physical memory of a child VM by
+// means of the LeechCore vmm:// device. The parent Vmm must stay open for as long as
+// the returned Vmm is in use. Extra args are appended to the startup arguments.
+func (vmm *Vmm) OpenChildVM(ctx context.Context, vm VMEntry, args ...string) (*Vmm, error) {
+	if !vm.Active {
+		return nil, ErrVMNotActive
+	}
+
+	device := fmt.Sprintf("vmm://hvmm=0x%x,hvm=0x%x,max=0x%x", uintptr(unsafe.Pointer(vmm.handle)), vm.Handle, vm.GpaMax)
+
+	resultChan := make(chan struct {
+		vmm *Vmm
+		err error
+	}, 1)
+
+	go func() {
+		child, err := NewVmm(append([]string{"-device", device}, args...)...)
+		resultChan <- struct {
+			vmm *Vmm
+			err error
+		}{child, err}
+	}()
+
+	select {
+	case <-ctx.Done():
+		go func() {
+			if result := <-resultChan; result.vmm != nil {
+				result.vmm.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	case result := <-resultChan:
+		return result.vmm, result.err
+	}
+}
@@ -85,3 +85,8 @@ func getMemSize(ptr C.PVOID) uint64 {
 
 	return uint64(C.VMMDLL_MemSize(ptr))
 }
+
+// readCString reads the C string referenced by the pointer stored at ptr.
+func readCString(ptr unsafe.Pointer) string {
+	return C.GoString(*(**C.char)(ptr))
+}